@@ -3,85 +3,257 @@ package keycdn
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // BaseURL is the KeyCDN API endpoint
 const BaseURL = "https://api.keycdn.com"
 
-// Client is the API client
+// Version is the version of this package
+const Version = "0.1.0"
+
+// DefaultUserAgent identifies this package to the API
+const DefaultUserAgent = "keycdn-go/" + Version
+
+// DefaultMaxResponseSize is the default limit for the size of response bodies
+const DefaultMaxResponseSize = 10 << 20
+
+// Client is the API client. It is safe for concurrent use.
 type Client struct {
-	apikey string
+	apikey *keyHolder
 	Base   string
 	http   *http.Client
+	// ownTransport is the transport created by the transport options
+	ownTransport *http.Transport
+	// maxResponseSize limits the size of response bodies
+	maxResponseSize int64
+	limiter         *rateLimiter
+	names           *nameIndex
+	idempotencyKeys bool
+	defaultTimeout  time.Duration
+	timeouts        map[string]time.Duration
+	userAgent       string
+	appName         string
+	debug           func(format string, args ...interface{})
+	middleware      []Middleware
+	tracer          Tracer
+	metrics         Metrics
+	breaker         *circuitBreaker
+	credentials     CredentialProvider
+	authMode        AuthMode
+	clientTrace     func(method, endpoint string) *httptrace.ClientTrace
+	timings         func(RequestTimings)
+	rateLimits      *rateLimitTracker
+	compressMin     int
+	cache           *responseCache
+	flights         *flightGroup
+	dryRun          bool
+	dryRunLog       func(format string, args ...interface{})
+	strict          bool
+	onUnknownField  func(file, field string)
+	retry           retryPolicy
+	retryBudget     *rateLimiter
+	clock           Clock
+	onWarning       func(file, description string)
+	// lookupCNAME and servedCertificate access DNS and the edge servers
+	// for EnableLetsEncrypt
+	lookupCNAME       func(ctx context.Context, host string) (string, error)
+	servedCertificate func(ctx context.Context, host string) (*x509.Certificate, error)
 }
 
-// New creates a new API client with the given API key
-func New(key string) Client {
-	return Client{
-		apikey: key,
-		Base:   BaseURL,
+// New creates a new API client with the given API key. It fails if the key
+// is empty, unless a CredentialProvider is configured, or if the options
+// result in an invalid configuration.
+func New(key string, opts ...Option) (*Client, error) {
+	c := &Client{
+		apikey:            &keyHolder{key: key},
+		Base:              BaseURL,
+		maxResponseSize:   DefaultMaxResponseSize,
+		userAgent:         DefaultUserAgent,
+		retry:             retryPolicy{maxAttempts: 1},
+		rateLimits:        &rateLimitTracker{},
+		clock:             realClock{},
+		lookupCNAME:       net.DefaultResolver.LookupCNAME,
+		servedCertificate: servedCertificate,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
 	}
+	c.useClock()
+	c.http = c.wrapMiddleware()
+	return c, nil
+}
+
+// validate checks the configuration of a new client
+func (c *Client) validate() error {
+	if c.credentials == nil && strings.TrimSpace(c.apikey.get()) == "" {
+		return errors.New("API key is empty")
+	}
+	u, err := url.Parse(c.Base)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", c.Base, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: not an absolute HTTP URL", c.Base)
+	}
+	if c.defaultTimeout < 0 {
+		return fmt.Errorf("invalid default timeout %s", c.defaultTimeout)
+	}
+	for endpoint, d := range c.timeouts {
+		if d < 0 {
+			return fmt.Errorf("invalid timeout %s for %s", d, endpoint)
+		}
+	}
+	if c.retry.baseDelay < 0 {
+		return fmt.Errorf("invalid retry delay %s", c.retry.baseDelay)
+	}
+	return nil
+}
+
+// Close releases the resources held by the client, i.e. idle connections
+// and cached data. The shared http.DefaultClient is left untouched.
+func (c *Client) Close() error {
+	c.InvalidateCache()
+	if c.http != nil {
+		c.http.CloseIdleConnections()
+	}
+	if c.ownTransport != nil {
+		c.ownTransport.CloseIdleConnections()
+	}
+	return nil
 }
 
 type response struct {
-	Status      string `json:"status"`
+	Status      status `json:"status"`
 	Description string `json:"description"`
 }
 
-// Zone is a distribution zone/property
+// status is the status of a response. Most endpoints report it as a string
+// like "success" but some return a numeric code instead. Codes in the 2xx
+// range are mapped to "success", all others are kept as their decimal
+// representation.
+type status string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *status) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		*s = status(str)
+		return nil
+	}
+	var code int
+	if err := json.Unmarshal(b, &code); err != nil {
+		return fmt.Errorf("status is neither a string nor a number: %s", b)
+	}
+	if code >= 200 && code < 300 {
+		*s = "success"
+		return nil
+	}
+	*s = status(strconv.Itoa(code))
+	return nil
+}
+
+// Zone is a distribution zone/property. It holds the settings of pull and
+// push zones alike, Typed returns a view with only the settings that apply
+// to the type of the zone.
 type Zone struct {
-	ID                      uint64
-	Name                    string
-	Status                  string
-	Type                    string
-	ForceDownload           bool
-	CORS                    bool
-	Gzip                    bool
-	Expire                  int
-	HTTP2                   bool
-	SecureToken             bool
-	SecureTokenKey          string
-	SSLCert                 string
-	CustomSSLKey            string
-	CunstomSSLCert          string
-	ForceSSL                bool
-	OriginURL               string
-	CacheMaxExpire          int
-	CacheIgnoreCacheControl bool
-	CacheIgnoreQueryString  bool
-	CacheStripCookies       bool
-	CachePullKey            string
-	CacheCanonical          bool
-	CacheRobots             bool
-}
-
-type zonesResp struct {
-	response
-	Data map[string][]zoneResp
+	ID                      uint64     `json:"id"`
+	Name                    string     `json:"name"`
+	Status                  ZoneStatus `json:"status"`
+	Type                    ZoneType   `json:"type"`
+	ForceDownload           bool       `json:"forcedownload"`
+	CORS                    bool       `json:"cors"`
+	Gzip                    bool       `json:"gzip"`
+	Expire                  int        `json:"expire"`
+	HTTP2                   bool       `json:"http2"`
+	SecureToken             bool       `json:"securetoken"`
+	SecureTokenKey          string     `json:"securetokenkey"`
+	SSLCert                 string     `json:"sslcert"`
+	CustomSSLKey            string     `json:"customsslkey"`
+	CustomSSLCert           string     `json:"customsslcert"`
+	ForceSSL                bool       `json:"forcessl"`
+	OriginURL               string     `json:"originurl"`
+	CacheMaxExpire          int        `json:"cachemaxexpire"`
+	CacheIgnoreCacheControl bool       `json:"cacheignorecachecontrol"`
+	CacheIgnoreQueryString  bool       `json:"cacheignorequerystring"`
+	CacheStripCookies       bool       `json:"cachestripcookies"`
+	CachePullKey            string     `json:"cachepullkey"`
+	CacheCanonical          bool       `json:"cachecanonical"`
+	CacheRobots             bool       `json:"cacherobots"`
+	// CacheHostHeader forwards the Host header of the client to the origin
+	CacheHostHeader bool `json:"cachehostheader"`
+	// OriginHostHeader overrides the Host header sent to the origin, e.g.
+	// for origins which route by host name
+	OriginHostHeader string `json:"originhostheader"`
+	// OriginShield routes cache misses through a single shield location
+	// to reduce the load on the origin
+	OriginShield bool `json:"originshield"`
+	// ImageProcessing enables on the fly image optimization and
+	// transformation through query parameters
+	ImageProcessing bool `json:"imageprocessing"`
+	// WebP converts images to WebP for clients accepting it. It requires
+	// ImageProcessing.
+	WebP bool `json:"webp"`
+
+	// CunstomSSLCert is the custom SSL certificate.
+	//
+	// Deprecated: Use CustomSSLCert. The field is still filled when zones
+	// are read and used when CustomSSLCert is empty.
+	CunstomSSLCert string `json:"-"`
+}
+
+// customSSLCert returns the custom certificate, falling back to the
+// deprecated field
+func (z *Zone) customSSLCert() *string {
+	if z.CustomSSLCert == "" {
+		z.CustomSSLCert = z.CunstomSSLCert
+	}
+	return &z.CustomSSLCert
 }
 
 type zoneResp map[string]string
 
+// UnmarshalJSON implements json.Unmarshaler
+func (z *zoneResp) UnmarshalJSON(b []byte) error {
+	m, err := unmarshalFlexMap(b)
+	*z = m
+	return err
+}
+
 // ToZone converts a zone response to a proper Zone object
 func (z zoneResp) ToZone() Zone {
 	zone := Zone{}
 	if idStr, found := z["id"]; found {
-		id, err := strconv.ParseUint(idStr, 10, 64)
+		id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 64)
 		if err == nil {
 			zone.ID = id
 		}
 	}
-	if name, found := z["name"]; found {
-		zone.Name = name
+	for _, f := range zoneFields {
+		if v, found := z[f.Param]; found {
+			f.parse(&zone, v)
+		}
 	}
-	// TODO(dschulz) fill out other fields as well
+	zone.CunstomSSLCert = zone.CustomSSLCert
 	return zone
 }
 
@@ -92,39 +264,38 @@ type stateStatResponse struct {
 
 type stateAmountResp map[string]string
 
+// UnmarshalJSON implements json.Unmarshaler
+func (s *stateAmountResp) UnmarshalJSON(b []byte) error {
+	m, err := unmarshalFlexMap(b)
+	*s = m
+	return err
+}
+
 // Get is TODO(dschulz) undocumented
 func (s stateAmountResp) Get(key string) uint64 {
-	if v, found := s[key]; found {
-		iv, err := strconv.Atoi(v)
-		if err != nil {
-			return 0
-		}
-		return uint64(iv)
-	}
-	return 0
+	n, _ := parseAmount(s[key])
+	return n
 }
 
 type trafficAmountResp struct {
-	Amount    string `json:"amount"`
-	Timestamp string `json:"timestamp"`
+	Amount    flexString `json:"amount"`
+	Timestamp flexString `json:"timestamp"`
 }
 
 // Count is TODO(dschulz) undocumented
 func (t trafficAmountResp) Count() uint64 {
-	iv, err := strconv.Atoi(t.Amount)
-	if err != nil {
-		return 0
-	}
-	return uint64(iv)
+	n, _ := parseAmount(string(t.Amount))
+	return n
 }
 
-// Time is TODO(dschulz) undocumented
-func (t trafficAmountResp) Time() time.Time {
-	iv, err := strconv.Atoi(t.Timestamp)
-	if err != nil {
-		return time.Now()
-	}
-	return time.Unix(int64(iv), 0)
+// Time returns the timestamp of the amount, or the current time of clock
+// if the API sent none
+func (t trafficAmountResp) Time(clock Clock) time.Time {
+	secs, ok := parseAmount(string(t.Timestamp))
+	if !ok {
+		return clock.Now()
+	}
+	return time.Unix(int64(secs), 0)
 }
 
 type trafficResponse struct {
@@ -133,45 +304,72 @@ type trafficResponse struct {
 }
 
 // Zones returns all the available zones
-func (c Client) Zones() (map[uint64]Zone, error) {
+func (c *Client) Zones(ctx context.Context) (map[uint64]Zone, error) {
 	zones := make(map[uint64]Zone, 2)
-	b, err := c.get("/zones.json", map[string]string{})
-	if err != nil {
-		return zones, err
+	it := c.ZoneIterator(ctx)
+	for it.Next() {
+		zone := it.Value()
+		zones[zone.ID] = zone
+	}
+	return zones, it.Err()
+}
+
+// ZoneIterator iterates over all zones without fetching them up front
+func (c *Client) ZoneIterator(ctx context.Context) *Iterator[Zone] {
+	return listIterator(ctx, c, "/zones.json", nil, "zones", zoneResp.ToZone)
+}
+
+// ZoneFilter restricts the set of zones returned by ZonesFiltered. Empty
+// fields match any value.
+type ZoneFilter struct {
+	Status ZoneStatus
+	Type   ZoneType
+}
+
+// Match returns true if the zone satisfies all fields of the filter
+func (f ZoneFilter) Match(z Zone) bool {
+	if f.Status != "" && f.Status != z.Status {
+		return false
+	}
+	if f.Type != "" && f.Type != z.Type {
+		return false
 	}
-	var zr zonesResp
-	err = json.Unmarshal(b, &zr)
+	return true
+}
+
+// ZonesFiltered returns only the zones matching the given filter, e.g. only
+// active pull zones. The API has no server-side filtering for zones so the
+// filter is applied on the client.
+func (c *Client) ZonesFiltered(ctx context.Context, filter ZoneFilter) (map[uint64]Zone, error) {
+	zones, err := c.Zones(ctx)
 	if err != nil {
 		return zones, err
 	}
-	if _, found := zr.Data["zones"]; !found {
-		return zones, fmt.Errorf("zones not found in data")
-	}
-	for _, z := range zr.Data["zones"] {
-		zone := z.ToZone()
-		zones[zone.ID] = zone
+	for id, zone := range zones {
+		if !filter.Match(zone) {
+			delete(zones, id)
+		}
 	}
 	return zones, nil
 }
 
-// Traffic returns the traffic stats for a zone and interval
-func (c Client) Traffic(zoneID uint64, from, to time.Time) (uint64, error) {
-	args := make(map[string]string, 4)
-	args["zone_id"] = strconv.FormatUint(zoneID, 10)
-	args["start"] = strconv.Itoa(int(from.Unix()))
-	args["end"] = strconv.Itoa(int(to.Unix()))
+// Traffic returns the traffic stats for a zone and interval. It returns
+// ErrNoData if the API reported no data for the interval.
+func (c *Client) Traffic(ctx context.Context, zoneID uint64, from, to time.Time) (uint64, error) {
+	args := reportArgs(zoneID, from, to)
 	args["interval"] = "hour"
-	b, err := c.get("/reports/traffic.json", args)
-	if err != nil {
-		return 0, err
-	}
 	var tr trafficResponse
-	err = json.Unmarshal(b, &tr)
-	if err != nil {
+	if err := c.getJSON(ctx, "/reports/traffic.json", args, &tr); err != nil {
 		return 0, err
 	}
+	if tr.Status != "" && tr.Status != "success" {
+		return 0, statusError("/reports/traffic.json", tr.response, "Failed to get traffic of Zone %d", zoneID)
+	}
 	if _, found := tr.Data["stats"]; !found {
-		return 0, fmt.Errorf("stats not found in data")
+		return 0, ErrStatsMissing
+	}
+	if len(tr.Data["stats"]) == 0 {
+		return 0, ErrNoData
 	}
 	var sum uint64
 	for _, a := range tr.Data["stats"] {
@@ -180,25 +378,24 @@ func (c Client) Traffic(zoneID uint64, from, to time.Time) (uint64, error) {
 	return sum, nil
 }
 
-// Stats returns simple stats for the given zone and interval
-func (c Client) Stats(zoneID uint64, from, to time.Time) (map[string]uint64, error) {
+// Stats returns simple stats for the given zone and interval. It returns
+// ErrNoData if the API reported no data for the interval.
+func (c *Client) Stats(ctx context.Context, zoneID uint64, from, to time.Time) (map[string]uint64, error) {
 	ret := make(map[string]uint64, 4)
-	args := make(map[string]string, 4)
-	args["zone_id"] = strconv.FormatUint(zoneID, 10)
-	args["start"] = strconv.Itoa(int(from.Unix()))
-	args["end"] = strconv.Itoa(int(to.Unix()))
+	args := reportArgs(zoneID, from, to)
 	args["interval"] = "hour"
-	b, err := c.get("/reports/statestats.json", args)
-	if err != nil {
-		return ret, err
-	}
 	var ssr stateStatResponse
-	err = json.Unmarshal(b, &ssr)
-	if err != nil {
+	if err := c.getJSON(ctx, "/reports/statestats.json", args, &ssr); err != nil {
 		return ret, err
 	}
+	if ssr.Status != "" && ssr.Status != "success" {
+		return ret, statusError("/reports/statestats.json", ssr.response, "Failed to get stats of Zone %d", zoneID)
+	}
 	if _, found := ssr.Data["stats"]; !found {
-		return ret, fmt.Errorf("stats not found in data")
+		return ret, ErrStatsMissing
+	}
+	if len(ssr.Data["stats"]) == 0 {
+		return ret, ErrNoData
 	}
 	for _, a := range ssr.Data["stats"] {
 		for _, k := range []string{"totalcachehit", "totalcachemiss", "totalsuccess", "totalerror"} {
@@ -208,22 +405,16 @@ func (c Client) Stats(zoneID uint64, from, to time.Time) (map[string]uint64, err
 	return ret, nil
 }
 
-// PurgeZoneCache will purge the given zone cache
-func (c Client) PurgeZoneCache(zoneID uint64) error {
-	zone := strconv.FormatUint(zoneID, 10)
-	b, err := c.get("/zones/purge/"+zone+".json", nil)
+// PurgeZoneCache will purge the given zone cache. Like all purge methods it
+// returns once the API accepted the request, the purge itself propagates to
+// the edge servers asynchronously.
+func (c *Client) PurgeZoneCache(ctx context.Context, zoneID uint64) error {
+	file := "/zones/purge/" + strconv.FormatUint(zoneID, 10) + ".json"
+	b, err := c.get(ctx, file, nil)
 	if err != nil {
 		return err
 	}
-	var resp response
-	err = json.Unmarshal(b, &resp)
-	if err != nil {
-		return err
-	}
-	if resp.Status != "success" {
-		return fmt.Errorf("Failed to purge Zone %d: %s", zoneID, resp.Description)
-	}
-	return nil
+	return c.checkResponse(file, b, fmt.Sprintf("Failed to purge Zone %d", zoneID))
 }
 
 // URLs is an URL list
@@ -232,32 +423,95 @@ type URLs struct {
 }
 
 // PurgeZoneURL will purge a given list of URLs from a zone cache
-func (c Client) PurgeZoneURL(zoneID uint64, urls []string) error {
-	zones, err := c.Zones()
+func (c *Client) PurgeZoneURL(ctx context.Context, zoneID uint64, urls []string) error {
+	zones, err := c.Zones(ctx)
 	if err != nil {
 		return err
 	}
 	zone, found := zones[zoneID]
 	if !found {
-		return fmt.Errorf("Zone %d not found", zoneID)
+		return fmt.Errorf("Failed to purge Zone %d: %w", zoneID, ErrZoneNotFound)
 	}
 	// TODO check urls have the correct prefix
 	_ = zone
-	zID := strconv.FormatUint(zoneID, 10)
+	file := "/zones/purgeurl/" + strconv.FormatUint(zoneID, 10) + ".json"
 	u := URLs{URLs: urls}
-	b, err := c.delete("/zones/purgeurl/"+zID+".json", u)
+	b, err := c.delete(ctx, file, u)
 	if err != nil {
 		return err
 	}
-	var resp response
-	err = json.Unmarshal(b, &resp)
+	return c.checkResponse(file, b, fmt.Sprintf("Failed to purge Zone %d", zoneID))
+}
+
+// ExpandURLVariants returns the URLs of all cached variants of base, one for
+// each query string, e.g. "img.jpg?w=100" and "img.jpg?w=200" for the
+// queries "w=100" and "w=200". A leading "?" in a query is optional and an
+// empty query stands for base itself. Queries are appended with "&" if base
+// already has a query string. Duplicates are removed, the order is kept.
+func ExpandURLVariants(base string, queries []string) []string {
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	seen := make(map[string]bool, len(queries))
+	urls := make([]string, 0, len(queries))
+	for _, q := range queries {
+		q = strings.TrimPrefix(q, "?")
+		u := base
+		if q != "" {
+			u += sep + q
+		}
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// PurgeChangedURLs purges only those URLs whose modification time is after
+// since and returns them. No request is sent if nothing changed.
+func (c *Client) PurgeChangedURLs(ctx context.Context, zoneID uint64, urls map[string]time.Time, since time.Time) ([]string, error) {
+	changed := make([]string, 0, len(urls))
+	for u, mtime := range urls {
+		if mtime.After(since) {
+			changed = append(changed, u)
+		}
+	}
+	if len(changed) == 0 {
+		return changed, nil
+	}
+	sort.Strings(changed)
+	if err := c.PurgeZoneURL(ctx, zoneID, changed); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// Prefixes is a list of path prefixes to purge
+type Prefixes struct {
+	URLs     []string `json:"urls"`
+	Wildcard bool     `json:"wildcard"`
+}
+
+// PurgeZonePrefix will purge all cached URLs starting with one of the given
+// prefixes, e.g. "zone-1.kxcdn.com/static/". A trailing "*" is added to each
+// prefix if it is missing. Prefix purges are not available on all plans.
+func (c *Client) PurgeZonePrefix(ctx context.Context, zoneID uint64, prefixes []string) error {
+	file := "/zones/purgeurl/" + strconv.FormatUint(zoneID, 10) + ".json"
+	p := Prefixes{URLs: make([]string, 0, len(prefixes)), Wildcard: true}
+	for _, prefix := range prefixes {
+		if !strings.HasSuffix(prefix, "*") {
+			prefix += "*"
+		}
+		p.URLs = append(p.URLs, prefix)
+	}
+	b, err := c.delete(ctx, file, p)
 	if err != nil {
 		return err
 	}
-	if resp.Status != "success" {
-		return fmt.Errorf("Failed to purge Zone %d: %s", zoneID, resp.Description)
-	}
-	return nil
+	return c.checkResponse(file, b, fmt.Sprintf("Failed to purge Zone %d", zoneID))
 }
 
 // Tags is a set of tags
@@ -266,62 +520,362 @@ type Tags struct {
 }
 
 // PurgeZoneTag will purge all tagged items from the zone
-func (c Client) PurgeZoneTag(zoneID uint64, tags []string) error {
-	zID := strconv.FormatUint(zoneID, 10)
+func (c *Client) PurgeZoneTag(ctx context.Context, zoneID uint64, tags []string) error {
+	file := "/zones/purgetag/" + strconv.FormatUint(zoneID, 10) + ".json"
 	t := Tags{Tags: tags}
-	b, err := c.delete("/zones/purgetag/"+zID+".json", t)
+	b, err := c.delete(ctx, file, t)
 	if err != nil {
 		return err
 	}
-	var resp response
-	err = json.Unmarshal(b, &resp)
-	if err != nil {
-		return err
+	return c.checkResponse(file, b, fmt.Sprintf("Failed to purge Zone %d", zoneID))
+}
+
+func (c *Client) get(ctx context.Context, file string, args map[string]string) ([]byte, error) {
+	url := c.url(file, args)
+	ttl := c.cache.ttl(file)
+	if ttl > 0 {
+		if b, found := c.cache.get(url); found {
+			return b, nil
+		}
 	}
-	if resp.Status != "success" {
-		return fmt.Errorf("Failed to purge Zone %d: %s", zoneID, resp.Description)
+	fetch := func(ctx context.Context) ([]byte, error) {
+		res := c.do(ctx, "GET", file, url, nil, http.Header{}, nil)
+		return res.body, res.err
 	}
-	return nil
+	var b []byte
+	var err error
+	if mutating("GET", file) {
+		b, err = fetch(ctx)
+	} else {
+		b, err = c.flights.do(ctx, url, fetch)
+	}
+	if err == nil && ttl > 0 {
+		c.cache.set(url, b, ttl)
+	}
+	return b, err
 }
 
-func (c Client) get(file string, args map[string]string) ([]byte, error) {
+// getJSON fetches file and decodes the JSON response into v. The body is
+// decoded while it is read instead of being buffered first, which keeps the
+// memory usage of large reports low. Responses of cached endpoints, all
+// responses in strict mode or with request coalescing and responses whose
+// meta data was requested with WithResponseMeta are buffered as usual.
+func (c *Client) getJSON(ctx context.Context, file string, args map[string]string, v interface{}) error {
+	if c.cache.ttl(file) > 0 || c.strict || c.flights != nil || wantsResponseMeta(ctx) {
+		b, err := c.get(ctx, file, args)
+		if err != nil {
+			return err
+		}
+		return c.unmarshal(file, b, v)
+	}
+	res := c.do(ctx, "GET", file, c.url(file, args), nil, http.Header{}, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(v)
+	})
+	return res.err
+}
+
+func (c *Client) url(file string, args map[string]string) string {
 	vs := url.Values{}
 	for k, v := range args {
 		vs.Set(k, v)
 	}
-	url := c.Base + file + "?" + vs.Encode()
+	return c.Base + file + "?" + vs.Encode()
+}
+
+// do sends a request and returns the result of the last attempt. Requests failing with a
+// network error or a 5xx status are retried with exponential backoff if
+// retries are enabled and the method is idempotent. POST requests are only
+// retried if they carry an idempotency key. If decode is not nil a successful
+// response is passed to it as a stream and no body is returned.
+func (c *Client) do(ctx context.Context, method, file, u string, body []byte, header http.Header, decode func(io.Reader) error) (res result) {
+	defer func() { storeResponseMeta(ctx, res, c.clock.Now()) }()
+	if c.dryRun && mutating(method, file) {
+		if c.dryRunLog != nil {
+			c.dryRunLog("keycdn: dry run: %s %s %s", method, u, truncate(string(body), maxDebugBody))
+		}
+		return result{body: dryRunBody, statusCode: http.StatusOK}
+	}
+	if !c.breaker.allow() {
+		return result{err: fmt.Errorf("%s %s: %w", method, file, ErrCircuitOpen)}
+	}
+	ctx, cancel := c.requestContext(ctx, file)
+	defer cancel()
+	ctx, span := c.startSpan(ctx, method, file, u)
+	start := c.clock.Now()
+
+	idempotent := method != "POST" || header.Get("Idempotency-Key") != ""
+	attempt, throttled := 1, 0
+	for ; ; attempt++ {
+		res = c.attempt(ctx, method, file, u, body, header, decode)
+		if res.err == nil || !res.retry || !idempotent {
+			break
+		}
+		delay := c.retry.backoff(attempt - throttled)
+		if res.statusCode == http.StatusTooManyRequests {
+			if throttled >= maxThrottledRetries {
+				break
+			}
+			throttled++
+			delay = res.retryAfter
+			if delay <= 0 {
+				delay = minThrottleDelay
+			}
+		} else if attempt-throttled >= c.retry.maxAttempts {
+			break
+		}
+		if !c.retryBudget.Allow() || !c.sleep(ctx, delay) {
+			break
+		}
+	}
+	// only upstream outcomes count, canceled calls or missing credentials
+	// say nothing about the health of the API
+	switch {
+	case res.err != nil && res.retry && res.statusCode != http.StatusTooManyRequests:
+		c.breaker.record(true)
+	case res.err == nil && res.statusCode >= 200 && res.statusCode < 300:
+		c.breaker.record(false)
+	}
+	endSpan(span, res.statusCode, attempt, res.err)
+	c.observe(method, file, res.statusCode, res.err, start)
+	return res
+}
+
+// sleep waits for d and returns false if the context was done before
+func (c *Client) sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-c.clock.After(d):
+		return true
+	}
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// result is the outcome of a single attempt
+type result struct {
+	body       []byte
+	statusCode int
+	header     http.Header
+	// retry is true if the request failed transiently
+	retry bool
+	// retryAfter is the delay requested by a 429 response, if any
+	retryAfter time.Duration
+	err        error
+}
+
+// attempt sends a request once
+func (c *Client) attempt(ctx context.Context, method, file, u string, body []byte, header http.Header, decode func(io.Reader) error) result {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
 	if err != nil {
-		return []byte{}, err
+		return result{err: fmt.Errorf("%s %s: %w", method, file, err)}
 	}
-	req.SetBasicAuth(c.apikey, "")
-	resp, err := http.DefaultClient.Do(req)
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	key, err := c.apiKey(ctx)
 	if err != nil {
-		return []byte{}, err
+		return result{err: fmt.Errorf("%s %s: failed to get API key: %w", method, file, err)}
+	}
+	c.authenticate(req, key)
+	req.Header.Set("Accept-Encoding", "gzip")
+	if ua := strings.TrimSpace(c.userAgent + " " + c.appName); ua != "" {
+		req.Header.Set("User-Agent", ua)
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return result{err: fmt.Errorf("%s %s: %w", method, file, err)}
+	}
+	c.debugf(key, "keycdn: %s %s %s", method, u, body)
+	req, traced := c.traceRequest(req, method, file)
+	resp, err := c.client().Do(req)
+	traced()
+	if err != nil {
+		c.debugf(key, "keycdn: %s %s failed: %s", method, u, err.Error())
+		return result{retry: ctx.Err() == nil, err: fmt.Errorf("%s %s: %w", method, file, err)}
 	}
 	defer resp.Body.Close()
-	return ioutil.ReadAll(resp.Body)
+	c.rateLimits.update(resp.Header, c.clock.Now())
+	respBody, err := decompressedBody(resp)
+	if err != nil {
+		return result{statusCode: resp.StatusCode, header: resp.Header, err: fmt.Errorf("%s %s (HTTP %d): %w", method, file, resp.StatusCode, err)}
+	}
+	if decode != nil && resp.StatusCode < 400 {
+		c.debugf(key, "keycdn: %s %s -> HTTP %d: <streamed>", method, u, resp.StatusCode)
+		if err := decode(c.limitBody(respBody)); err != nil {
+			return result{statusCode: resp.StatusCode, header: resp.Header, err: fmt.Errorf("%s %s (HTTP %d): %w", method, file, resp.StatusCode, err)}
+		}
+		return result{statusCode: resp.StatusCode, header: resp.Header}
+	}
+	b, err := c.readBody(respBody)
+	c.debugf(key, "keycdn: %s %s -> HTTP %d: %s", method, u, resp.StatusCode, b)
+	if err != nil {
+		return result{
+			body:       b,
+			statusCode: resp.StatusCode,
+			header:     resp.Header,
+			retry:      ctx.Err() == nil,
+			err:        fmt.Errorf("%s %s (HTTP %d): %w", method, file, resp.StatusCode, err),
+		}
+	}
+	res := result{body: b, statusCode: resp.StatusCode, header: resp.Header}
+	if resp.StatusCode >= 400 {
+		res.err = httpError(method, file, resp.StatusCode, b)
+		res.retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		res.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), c.clock.Now())
+	}
+	return res
 }
 
-func (c Client) delete(file string, body interface{}) ([]byte, error) {
-	url := c.Base + file
+// requestContext returns the context of a single request to file. Unless ctx
+// already has a deadline the timeout configured for the endpoint or the
+// default timeout of the client is applied.
+func (c *Client) requestContext(ctx context.Context, file string) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	timeout := c.defaultTimeout
+	if d, found := lookupEndpoint(c.timeouts, file); found {
+		timeout = d
+	}
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
 
-	b, err := json.Marshal(body)
+// readBody reads the whole body but fails if it exceeds the configured
+// maximum response size
+func (c *Client) readBody(r io.Reader) ([]byte, error) {
+	if c.maxResponseSize <= 0 {
+		return ioutil.ReadAll(r)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(r, c.maxResponseSize+1))
 	if err != nil {
-		return nil, err
+		return b, err
+	}
+	if int64(len(b)) > c.maxResponseSize {
+		return nil, fmt.Errorf("response body exceeds %d bytes", c.maxResponseSize)
 	}
+	return b, nil
+}
 
-	req, err := http.NewRequest("DELETE", url, bytes.NewBuffer(b))
-	if err != nil {
-		return nil, err
+// limitBody wraps r so that reading fails once it exceeds the configured
+// maximum response size
+func (c *Client) limitBody(r io.Reader) io.Reader {
+	if c.maxResponseSize <= 0 {
+		return r
+	}
+	return &limitedBody{r: r, max: c.maxResponseSize, left: c.maxResponseSize}
+}
+
+type limitedBody struct {
+	r         io.Reader
+	max, left int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, fmt.Errorf("response body exceeds %d bytes", l.max)
+	}
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, fmt.Errorf("response body exceeds %d bytes", l.max)
+	}
+	return n, err
+}
+
+// encoding selects how the body of a mutating request is serialized. Most
+// endpoints accept JSON but some of the older write endpoints only understand
+// form encoded parameters.
+type encoding int
+
+const (
+	encodingJSON encoding = iota
+	encodingForm
+)
+
+func (c *Client) post(ctx context.Context, file string, body interface{}, enc encoding) ([]byte, error) {
+	return c.send(ctx, "POST", file, body, enc)
+}
+
+func (c *Client) put(ctx context.Context, file string, body interface{}, enc encoding) ([]byte, error) {
+	return c.send(ctx, "PUT", file, body, enc)
+}
+
+func (c *Client) delete(ctx context.Context, file string, body interface{}) ([]byte, error) {
+	return c.send(ctx, "DELETE", file, body, encodingJSON)
+}
+
+func (c *Client) send(ctx context.Context, method, file string, body interface{}, enc encoding) ([]byte, error) {
+	res := c.request(ctx, method, file, nil, body, enc)
+	return res.body, res.err
+}
+
+// request encodes body and sends it to file with the optional query. No
+// body is sent if body is nil.
+func (c *Client) request(ctx context.Context, method, file string, query url.Values, body interface{}, enc encoding) result {
+	u := c.Base + file
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var b []byte
+	var contentType string
+	switch {
+	case body == nil:
+	case enc == encodingForm:
+		vs, ok := body.(url.Values)
+		if !ok {
+			return result{err: fmt.Errorf("form encoding requires url.Values, got %T", body)}
+		}
+		b = []byte(vs.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	default:
+		var err error
+		b, err = json.Marshal(body)
+		if err != nil {
+			return result{err: err}
+		}
+		contentType = "application/json"
 	}
-	req.SetBasicAuth(c.apikey, "")
-	req.Header.Add("Content-Type", "application/json")
-	resp, err := http.DefaultClient.Do(req)
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	b, compressed, err := c.compressBody(b)
 	if err != nil {
-		return nil, err
+		return result{err: fmt.Errorf("%s %s: %w", method, file, err)}
 	}
-	defer resp.Body.Close()
-	return ioutil.ReadAll(resp.Body)
+	if compressed {
+		header.Set("Content-Encoding", "gzip")
+	}
+	if c.idempotencyKeys {
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return result{err: fmt.Errorf("%s %s: %w", method, file, err)}
+		}
+		header.Set("Idempotency-Key", key)
+	}
+	res := c.do(ctx, method, file, u, b, header, nil)
+	if res.err == nil {
+		c.cache.clear()
+	}
+	return res
+}
+
+// newIdempotencyKey returns a random key identifying a logical mutating
+// operation
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
@@ -0,0 +1,152 @@
+package keycdn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// backupVersion is the version of the format written by ExportZones
+const backupVersion = 1
+
+// Backup is the format of ExportZones and ImportZones
+type Backup struct {
+	Version int          `json:"version"`
+	Zones   []ZoneBackup `json:"zones"`
+}
+
+// ZoneBackup is the complete configuration of a zone
+type ZoneBackup struct {
+	Zone      ZoneConfig `json:"zone"`
+	Aliases   []string   `json:"aliases,omitempty"`
+	Referrers []string   `json:"referrers,omitempty"`
+}
+
+// ExportZones writes the configuration of all zones including their
+// aliases and referrers as JSON to w. The output includes secure token and
+// custom SSL keys, so it must be stored safely.
+func ExportZones(ctx context.Context, api API, w io.Writer) error {
+	zones, err := api.Zones(ctx)
+	if err != nil {
+		return err
+	}
+	aliases, err := api.ZoneAliases(ctx)
+	if err != nil {
+		return err
+	}
+	referrers, err := api.ZoneReferrers(ctx)
+	if err != nil {
+		return err
+	}
+
+	b := Backup{Version: backupVersion}
+	idx := make(map[uint64]int, len(zones))
+	for _, z := range zones {
+		idx[z.ID] = len(b.Zones)
+		b.Zones = append(b.Zones, ZoneBackup{Zone: z.Config()})
+	}
+	for _, a := range aliases {
+		if i, found := idx[a.ZoneID]; found {
+			b.Zones[i].Aliases = append(b.Zones[i].Aliases, a.Name)
+		}
+	}
+	for _, r := range referrers {
+		if i, found := idx[r.ZoneID]; found {
+			b.Zones[i].Referrers = append(b.Zones[i].Referrers, r.Name)
+		}
+	}
+	sort.Slice(b.Zones, func(i, j int) bool {
+		return b.Zones[i].Zone.ID < b.Zones[j].Zone.ID
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return fmt.Errorf("Failed to export zones: %w", err)
+	}
+	return nil
+}
+
+// ImportZones restores zones written by ExportZones, e.g. into another
+// account. Zones are matched by name, so importing into the same account
+// updates the zones in place. Settings, including the status, are compared
+// like by ZoneConfig.Diff. Missing aliases are added
+// and the referrers of each zone are replaced with the exported ones.
+func ImportZones(ctx context.Context, api API, r io.Reader) error {
+	var b Backup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return fmt.Errorf("Failed to import zones: %w", err)
+	}
+	if b.Version != backupVersion {
+		return fmt.Errorf("Failed to import zones: unsupported version %d", b.Version)
+	}
+
+	zones, err := api.Zones(ctx)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]Zone, len(zones))
+	ambiguous := make(map[string]bool)
+	for _, z := range zones {
+		if _, found := byName[z.Name]; found {
+			ambiguous[z.Name] = true
+		}
+		byName[z.Name] = z
+	}
+	existing, err := api.ZoneAliases(ctx)
+	if err != nil {
+		return err
+	}
+	type aliasKey struct {
+		zoneID uint64
+		name   string
+	}
+	aliases := make(map[aliasKey]bool, len(existing))
+	for _, a := range existing {
+		aliases[aliasKey{a.ZoneID, a.Name}] = true
+	}
+
+	for _, zb := range b.Zones {
+		if ambiguous[zb.Zone.Name] {
+			return fmt.Errorf("Failed to import Zone %s: multiple zones named %q", zb.Zone.Name, zb.Zone.Name)
+		}
+		actual, found := byName[zb.Zone.Name]
+		z, err := importZone(ctx, api, zb.Zone, actual, found)
+		if err != nil {
+			return fmt.Errorf("Failed to import Zone %s: %w", zb.Zone.Name, err)
+		}
+		byName[z.Name] = z
+		for _, name := range zb.Aliases {
+			if aliases[aliasKey{z.ID, name}] {
+				continue
+			}
+			if _, err := api.AddZoneAlias(ctx, z.ID, name); err != nil {
+				return fmt.Errorf("Failed to import Zone %s: %w", zb.Zone.Name, err)
+			}
+		}
+		if _, err := api.SetZoneReferrers(ctx, z.ID, zb.Referrers); err != nil {
+			return fmt.Errorf("Failed to import Zone %s: %w", zb.Zone.Name, err)
+		}
+	}
+	return nil
+}
+
+// importZone creates the zone of the configuration unless it was found and
+// updates the settings which differ. New zones are updated as well since
+// the status can only be set after creating a zone.
+func importZone(ctx context.Context, api API, zc ZoneConfig, actual Zone, found bool) (Zone, error) {
+	if !found {
+		z, err := api.AddZone(ctx, zc.CreateRequest())
+		if err != nil {
+			return Zone{}, err
+		}
+		actual = z
+	}
+	d := zc.Diff(actual)
+	if len(d) == 0 {
+		return actual, nil
+	}
+	return api.UpdateZone(ctx, d.Update(actual.ID))
+}
@@ -0,0 +1,84 @@
+package keycdn_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+// countCalls returns how often each method of f was called
+func countCalls(f *keycdntest.Fake) map[string]int {
+	n := make(map[string]int)
+	for _, call := range f.Calls() {
+		n[call.Method]++
+	}
+	return n
+}
+
+func TestExportImportZones(t *testing.T) {
+	ctx := context.Background()
+	src := keycdntest.NewFake()
+	assets := src.SeedZone(keycdn.Zone{Name: "assets", OriginURL: "https://example.com", Gzip: true})
+	src.SeedZone(keycdn.Zone{Name: "images", Status: keycdn.ZoneStatusInactive, Expire: 60})
+	src.SeedZone(keycdn.Zone{Name: "videos", Status: keycdn.ZoneStatusPaused})
+	if _, err := src.AddZoneAlias(ctx, assets.ID, "cdn.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.SetZoneReferrers(ctx, assets.ID, []string{"example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := keycdn.ExportZones(ctx, src, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := keycdntest.NewFake()
+	dst.SeedZone(keycdn.Zone{Name: "assets", OriginURL: "https://old.example.com"})
+	if err := keycdn.ImportZones(ctx, dst, bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	// the zones are listed once instead of once per imported zone
+	calls := countCalls(dst)
+	if calls["Zones"] != 1 || calls["ZoneByName"] != 0 || calls["ZoneNameIndex"] != 0 {
+		t.Errorf("calls = %v, want a single Zones call", calls)
+	}
+	zones, _ := dst.Zones(ctx)
+	byName := make(map[string]keycdn.Zone)
+	for _, z := range zones {
+		byName[z.Name] = z
+	}
+	if len(zones) != 3 || byName["assets"].ID != 1 || byName["assets"].OriginURL != "https://example.com" || !byName["assets"].Gzip {
+		t.Errorf("zones = %+v, want assets updated in place and two new zones", zones)
+	}
+	if got := byName["images"]; got.Status != keycdn.ZoneStatusInactive || got.Expire != 60 {
+		t.Errorf("images = %+v, want it inactive with expire 60", got)
+	}
+	if got := byName["videos"]; got.Status != keycdn.ZoneStatusPaused {
+		t.Errorf("videos = %+v, want it paused", got)
+	}
+	aliases, _ := dst.ZoneAliases(ctx)
+	if len(aliases) != 1 || aliases[0].ZoneID != 1 || aliases[0].Name != "cdn.example.com" {
+		t.Errorf("aliases = %+v, want cdn.example.com of zone 1", aliases)
+	}
+	referrers, _ := dst.ZoneReferrers(ctx)
+	if len(referrers) != 1 || referrers[0].ZoneID != 1 || referrers[0].Name != "example.com" {
+		t.Errorf("referrers = %+v, want example.com of zone 1", referrers)
+	}
+}
+
+func TestImportZonesAmbiguousName(t *testing.T) {
+	ctx := context.Background()
+	dst := keycdntest.NewFake()
+	dst.SeedZone(keycdn.Zone{Name: "assets"})
+	dst.SeedZone(keycdn.Zone{Name: "assets"})
+
+	err := keycdn.ImportZones(ctx, dst, strings.NewReader(`{"version":1,"zones":[{"zone":{"name":"assets","type":"pull"}}]}`))
+	if err == nil || !strings.Contains(err.Error(), `multiple zones named "assets"`) {
+		t.Errorf("err = %v, want an error about the ambiguous name", err)
+	}
+}
@@ -0,0 +1,54 @@
+package keycdn
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker fails requests fast after a series of transient failures,
+// i.e. network errors, timeouts and 5xx responses
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	clock     Clock
+}
+
+// allow returns false while the breaker is open. Once the cool-down has
+// passed a single trial request is let through; if it fails the breaker
+// stays open for another cool-down period. A nil breaker always allows.
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.clock.Now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.openedAt = b.clock.Now()
+	return true
+}
+
+// record registers the outcome of a request which reached the API or
+// failed on the way there
+func (b *circuitBreaker) record(failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.clock.Now()
+	}
+}
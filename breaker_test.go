@@ -0,0 +1,45 @@
+package keycdn_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+func TestCircuitBreakerIgnoresNonUpstreamOutcomes(t *testing.T) {
+	ctx := context.Background()
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	s.Fail("/zones/1.json", http.StatusServiceUnavailable, 1)
+
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL), keycdn.WithCircuitBreaker(2, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Zone(ctx, z.ID); err == nil {
+		t.Fatal("first call did not fail")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.Zone(canceled, z.ID); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if _, err := c.Zone(ctx, 99); !errors.Is(err, keycdn.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+
+	s.Fail("/zones/1.json", http.StatusServiceUnavailable, 1)
+	if _, err := c.Zone(ctx, z.ID); err == nil || errors.Is(err, keycdn.ErrCircuitOpen) {
+		t.Fatalf("err = %v, want an upstream error", err)
+	}
+	if _, err := c.Zone(ctx, z.ID); !errors.Is(err, keycdn.ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen after two upstream failures", err)
+	}
+}
@@ -0,0 +1,104 @@
+package keycdn
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// responseCache caches the bodies of GET responses per URL. It is shared by
+// all copies of a Client.
+type responseCache struct {
+	mu      sync.Mutex
+	ttls    map[string]time.Duration
+	entries map[string]cacheEntry
+	clock   Clock
+}
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// WithCacheTTL caches successful responses of the given endpoint for ttl.
+// The endpoint is either an exact path like "/zones.json" or a prefix ending
+// in "/" like "/reports/". The option can be passed several times to cache
+// different endpoints with different TTLs. The cache is cleared by
+// InvalidateCache and by every successful POST, PUT or DELETE request.
+func WithCacheTTL(endpoint string, ttl time.Duration) Option {
+	return func(c *Client) {
+		if c.cache == nil {
+			c.cache = &responseCache{
+				ttls:    map[string]time.Duration{},
+				entries: map[string]cacheEntry{},
+			}
+		}
+		c.cache.ttls[endpoint] = ttl
+	}
+}
+
+// ttl returns the TTL for an endpoint, 0 if it is not cached. An exact
+// match wins over a prefix match. Purges are never cached even though
+// PurgeZoneCache uses GET.
+func (rc *responseCache) ttl(file string) time.Duration {
+	if rc == nil || strings.HasPrefix(file, "/zones/purge") {
+		return 0
+	}
+	ttl, _ := lookupEndpoint(rc.ttls, file)
+	return ttl
+}
+
+// lookupEndpoint returns the value configured for an endpoint. Keys are
+// either exact paths or prefixes ending in "/". An exact match wins over
+// the longest matching prefix.
+func lookupEndpoint[T any](m map[string]T, file string) (T, bool) {
+	if v, found := m[file]; found {
+		return v, true
+	}
+	var v T
+	longest := 0
+	for prefix, pv := range m {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(file, prefix) && len(prefix) > longest {
+			v, longest = pv, len(prefix)
+		}
+	}
+	return v, longest > 0
+}
+
+func (rc *responseCache) get(key string) ([]byte, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, found := rc.entries[key]
+	if !found || rc.clock.Now().After(e.expires) {
+		return nil, false
+	}
+	return append([]byte(nil), e.body...), true
+}
+
+func (rc *responseCache) set(key string, body []byte, ttl time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries[key] = cacheEntry{
+		body:    append([]byte(nil), body...),
+		expires: rc.clock.Now().Add(ttl),
+	}
+}
+
+func (rc *responseCache) clear() {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries = map[string]cacheEntry{}
+}
+
+// InvalidateCache drops all cached responses and the cached zone name index
+func (c *Client) InvalidateCache() {
+	c.cache.clear()
+	if c.names != nil {
+		c.names.mu.Lock()
+		c.names.index = nil
+		c.names.mu.Unlock()
+	}
+}
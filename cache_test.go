@@ -0,0 +1,130 @@
+package keycdn_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+// newCacheClient returns a client of s caching all zone endpoints for a
+// minute and the clock driving the cache
+func newCacheClient(t *testing.T, s *keycdntest.Server, opts ...keycdn.Option) (*keycdn.Client, *keycdntest.Clock) {
+	t.Helper()
+	clock := keycdntest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	opts = append([]keycdn.Option{keycdn.WithBaseURL(s.URL), keycdn.WithClock(clock), keycdn.WithCacheTTL("/zones/", time.Minute)}, opts...)
+	c, err := keycdn.New("key", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, clock
+}
+
+// zoneName fetches the zone id and returns its name
+func zoneName(t *testing.T, c *keycdn.Client, id uint64) string {
+	t.Helper()
+	z, err := c.Zone(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return z.Name
+}
+
+func TestCacheExpiry(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	c, clock := newCacheClient(t, s)
+
+	if name := zoneName(t, c, z.ID); name != "assets" {
+		t.Fatalf("name = %q, want assets", name)
+	}
+	s.Fake.SeedZone(keycdn.Zone{ID: z.ID, Name: "images"})
+	clock.Advance(time.Minute)
+	if name := zoneName(t, c, z.ID); name != "assets" {
+		t.Errorf("name within the TTL = %q, want the cached assets", name)
+	}
+	if n := s.Requests("/zones/1.json"); n != 1 {
+		t.Errorf("%d requests sent within the TTL, want 1", n)
+	}
+
+	clock.Advance(time.Second)
+	if name := zoneName(t, c, z.ID); name != "images" {
+		t.Errorf("name after the TTL = %q, want images", name)
+	}
+	if n := s.Requests("/zones/1.json"); n != 2 {
+		t.Errorf("%d requests sent after the TTL, want 2", n)
+	}
+}
+
+func TestCacheEndpoints(t *testing.T) {
+	ctx := context.Background()
+	s := keycdntest.NewServer()
+	defer s.Close()
+	first := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	second := s.Fake.SeedZone(keycdn.Zone{Name: "images"})
+	// the exact path wins over the prefix and disables caching
+	c, _ := newCacheClient(t, s, keycdn.WithCacheTTL("/zones/1.json", 0))
+
+	for i := 0; i < 2; i++ {
+		zoneName(t, c, first.ID)
+		zoneName(t, c, second.ID)
+		if _, err := c.ZoneAliases(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.PurgeZoneCache(ctx, second.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, want := range map[string]int{
+		"/zones/1.json":       2,
+		"/zones/2.json":       1,
+		"/zonealiases.json":   2,
+		"/zones/purge/2.json": 2,
+	} {
+		if n := s.Requests(path); n != want {
+			t.Errorf("%d requests to %s, want %d", n, path, want)
+		}
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	c, _ := newCacheClient(t, s)
+
+	zoneName(t, c, z.ID)
+	s.Fake.SeedZone(keycdn.Zone{ID: z.ID, Name: "images"})
+	c.InvalidateCache()
+	if name := zoneName(t, c, z.ID); name != "images" {
+		t.Errorf("name after InvalidateCache = %q, want images", name)
+	}
+
+	// a failed write keeps the cache
+	s.Fail("/zones/1.json", http.StatusInternalServerError, 1)
+	if _, err := c.EditZone(ctx, keycdn.Zone{ID: z.ID, Name: "videos"}); err == nil {
+		t.Fatal("EditZone succeeded, want the 500")
+	}
+	if name := zoneName(t, c, z.ID); name != "images" {
+		t.Errorf("name after a failed write = %q, want the cached images", name)
+	}
+	if n := s.Requests("/zones/1.json"); n != 3 {
+		t.Errorf("%d requests sent, want 3", n)
+	}
+
+	// a successful write clears it
+	if _, err := c.EditZone(ctx, keycdn.Zone{ID: z.ID, Name: "videos"}); err != nil {
+		t.Fatal(err)
+	}
+	if name := zoneName(t, c, z.ID); name != "videos" {
+		t.Errorf("name after a write = %q, want videos", name)
+	}
+	if n := s.Requests("/zones/1.json"); n != 5 {
+		t.Errorf("%d requests sent, want 5", n)
+	}
+}
@@ -0,0 +1,49 @@
+package keycdn
+
+import "time"
+
+// Clock provides the current time and timers to a Client. Replacing it, e.g.
+// with keycdntest.Clock, allows testing retries, caching, polling, the
+// circuit breaker and the rate limiter without sleeping.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WithClock makes the client use clock instead of the system clock for all
+// time based logic. It should only be used in tests.
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		if clock == nil {
+			clock = realClock{}
+		}
+		c.clock = clock
+	}
+}
+
+// useClock hands the clock of the client to the components created by the
+// options, which may have been passed before WithClock
+func (c *Client) useClock() {
+	if c.limiter != nil {
+		c.limiter.setClock(c.clock)
+	}
+	if c.retryBudget != nil {
+		c.retryBudget.setClock(c.clock)
+	}
+	if c.breaker != nil {
+		c.breaker.clock = c.clock
+	}
+	if c.cache != nil {
+		c.cache.clock = c.clock
+	}
+}
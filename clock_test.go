@@ -0,0 +1,118 @@
+package keycdn_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+func TestCircuitBreakerCooldown(t *testing.T) {
+	ctx := context.Background()
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	s.Fail("/zones/1.json", http.StatusServiceUnavailable, 2)
+
+	clock := keycdntest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL), keycdn.WithClock(clock), keycdn.WithCircuitBreaker(2, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.Zone(ctx, z.ID); err == nil || errors.Is(err, keycdn.ErrCircuitOpen) {
+			t.Fatalf("call %d: err = %v, want an upstream error", i, err)
+		}
+	}
+	if _, err := c.Zone(ctx, z.ID); !errors.Is(err, keycdn.ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+
+	clock.Advance(time.Minute - time.Second)
+	if _, err := c.Zone(ctx, z.ID); !errors.Is(err, keycdn.ErrCircuitOpen) {
+		t.Fatalf("err before the cool-down passed = %v, want ErrCircuitOpen", err)
+	}
+
+	clock.Advance(time.Second)
+	if _, err := c.Zone(ctx, z.ID); err != nil {
+		t.Fatalf("trial call after the cool-down: %v", err)
+	}
+	if _, err := c.Zone(ctx, z.ID); err != nil {
+		t.Fatalf("call after a successful trial: %v", err)
+	}
+}
+
+func TestClockAfter(t *testing.T) {
+	clock := keycdntest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ch := clock.After(time.Minute)
+	clock.Advance(30 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("timer fired early")
+	default:
+	}
+	clock.Advance(30 * time.Second)
+	select {
+	case got := <-ch:
+		if !got.Equal(clock.Now()) {
+			t.Errorf("timer fired at %s, want %s", got, clock.Now())
+		}
+	default:
+		t.Fatal("timer did not fire")
+	}
+}
+
+func TestTrafficTimeFallback(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := keycdntest.NewClock(now)
+
+	if got := keycdn.TrafficTime("", clock); !got.Equal(now) {
+		t.Errorf("missing timestamp = %s, want the clock time %s", got, now)
+	}
+	clock.Advance(time.Hour)
+	if got := keycdn.TrafficTime("garbage", clock); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("invalid timestamp = %s, want the clock time %s", got, now.Add(time.Hour))
+	}
+	if got := keycdn.TrafficTime("1700000000", clock); !got.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("timestamp = %s, want %s", got, time.Unix(1700000000, 0))
+	}
+}
+
+func TestSSLStatusExpiresWithinUsesClock(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	expires := now.Add(20 * 24 * time.Hour)
+	certPEM, keyPEM := keycdn.SelfSignedCert(t, "cdn.example.com", now.Add(-time.Hour), expires)
+
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets", SSLCert: keycdn.SSLCertCustom, CustomSSLCert: certPEM, CustomSSLKey: keyPEM})
+
+	clock := keycdntest.NewClock(now)
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL), keycdn.WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := c.ZoneSSLStatus(ctx, z.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Checked.Equal(now) {
+		t.Errorf("Checked = %s, want the clock time %s", status.Checked, now)
+	}
+	if !status.ExpiresWithin(30*24*time.Hour) || status.ExpiresWithin(10*24*time.Hour) {
+		t.Errorf("ExpiresWithin is not relative to the clock time, expires %s", status.Expires)
+	}
+
+	clock.Advance(15 * 24 * time.Hour)
+	if status, err = c.ZoneSSLStatus(ctx, z.ID); err != nil {
+		t.Fatal(err)
+	}
+	if !status.ExpiresWithin(10 * 24 * time.Hour) {
+		t.Errorf("certificate expiring in 5 days is not within 10 days")
+	}
+}
@@ -0,0 +1,71 @@
+package keycdn
+
+import (
+	"context"
+	"fmt"
+)
+
+// CloneOptions selects what CloneZone copies besides the zone settings
+type CloneOptions struct {
+	// Referrers copies the referrers of the source zone
+	Referrers bool
+	// Aliases maps each alias of the source zone to the alias of the
+	// clone, e.g. from cdn.example.com to cdn.staging.example.com. Aliases
+	// mapped to "" are skipped. If nil, no aliases are copied since an
+	// alias can only belong to one zone.
+	Aliases func(name string) string
+}
+
+// CloneZone creates a new zone with the given name and the settings of the
+// source zone, including its secure token key and certificate setup. The
+// clone starts with the default status. If copying the aliases or
+// referrers fails, the created zone is returned together with the error.
+func (c *Client) CloneZone(ctx context.Context, sourceZoneID uint64, newName string, opts CloneOptions) (Zone, error) {
+	src, err := c.Zone(ctx, sourceZoneID)
+	if err != nil {
+		return Zone{}, err
+	}
+	req := src.CreateRequest()
+	req.Name = newName
+	clone, err := c.AddZone(ctx, req)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	if opts.Referrers {
+		refs, err := c.ZoneReferrers(ctx)
+		if err != nil {
+			return clone, err
+		}
+		var names []string
+		for _, r := range refs {
+			if r.ZoneID == sourceZoneID {
+				names = append(names, r.Name)
+			}
+		}
+		if len(names) > 0 {
+			if _, err := c.SetZoneReferrers(ctx, clone.ID, names); err != nil {
+				return clone, err
+			}
+		}
+	}
+	if opts.Aliases != nil {
+		aliases, err := c.ZoneAliases(ctx)
+		if err != nil {
+			return clone, err
+		}
+		for _, a := range aliases {
+			if a.ZoneID != sourceZoneID {
+				continue
+			}
+			name := opts.Aliases(a.Name)
+			if name == "" {
+				continue
+			}
+			if _, err := c.AddZoneAlias(ctx, clone.ID, name); err != nil {
+				return clone, fmt.Errorf("Failed to clone alias %s of Zone %d: %w", a.Name, sourceZoneID, err)
+			}
+		}
+	}
+	return clone, nil
+}
@@ -0,0 +1,90 @@
+package keycdn
+
+import (
+	"context"
+	"sync"
+)
+
+// WithRequestCoalescing makes concurrent identical GET requests share a
+// single API call, e.g. when many goroutines ask for Zones at the same time.
+// The shared call is detached from the contexts of the callers, each
+// caller stops waiting when its own context is done and the call is
+// canceled once all callers are gone. Purges are never coalesced.
+func WithRequestCoalescing() Option {
+	return func(c *Client) {
+		c.flights = &flightGroup{calls: map[string]*flight{}}
+	}
+}
+
+// flightGroup deduplicates in-flight requests. It is shared by all copies of
+// a Client.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done     chan struct{}
+	cancel   context.CancelFunc
+	waiters  int
+	body     []byte
+	err      error
+	panicked interface{}
+}
+
+// do calls fn unless a call with the same key is in flight, in which case
+// it waits for that call and returns its result. A panic of fn is passed on
+// to all callers. A nil group always calls fn with ctx.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if g == nil {
+		return fn(ctx)
+	}
+	g.mu.Lock()
+	f, found := g.calls[key]
+	if !found {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = f
+		go g.run(callCtx, key, f, fn)
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		if f.panicked != nil {
+			panic(f.panicked)
+		}
+		return append([]byte(nil), f.body...), f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			// nobody is interested anymore, later callers start over
+			f.cancel()
+			g.forget(key, f)
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// run executes the shared call of f
+func (g *flightGroup) run(ctx context.Context, key string, f *flight, fn func(ctx context.Context) ([]byte, error)) {
+	defer func() {
+		f.panicked = recover()
+		g.mu.Lock()
+		g.forget(key, f)
+		g.mu.Unlock()
+		f.cancel()
+		close(f.done)
+	}()
+	f.body, f.err = fn(ctx)
+}
+
+// forget removes f unless it was already replaced, g.mu must be held
+func (g *flightGroup) forget(key string, f *flight) {
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,155 @@
+package keycdn
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingServer answers zone list requests once release is closed
+func blockingServer(t *testing.T, release <-chan struct{}) (*Client, *int32) {
+	t.Helper()
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		io.WriteString(w, `{"status":"success","data":{"zones":[{"id":"1","name":"assets"}]}}`)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := New("key", WithBaseURL(srv.URL), WithRequestCoalescing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, &requests
+}
+
+// waitForWaiters waits until n callers share the only flight of g
+func waitForWaiters(t *testing.T, g *flightGroup, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		waiters := 0
+		for _, f := range g.calls {
+			waiters = f.waiters
+		}
+		g.mu.Unlock()
+		if waiters == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("callers did not join the flight")
+}
+
+func TestCoalescingSurvivesCancelingCaller(t *testing.T) {
+	release := make(chan struct{})
+	c, requests := blockingServer(t, release)
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := c.get(first, "/zones.json", nil)
+		firstErr <- err
+	}()
+	for atomic.LoadInt32(requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	const others = 3
+	var wg sync.WaitGroup
+	errs := make([]error, others)
+	bodies := make([][]byte, others)
+	for i := 0; i < others; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i], errs[i] = c.get(context.Background(), "/zones.json", nil)
+		}(i)
+	}
+	waitForWaiters(t, c.flights, others+1)
+
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled caller: err = %v, want context.Canceled", err)
+	}
+	close(release)
+	wg.Wait()
+
+	for i := range errs {
+		if errs[i] != nil || len(bodies[i]) == 0 {
+			t.Errorf("caller %d: body %q, err %v", i, bodies[i], errs[i])
+		}
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("%d requests sent, want 1", n)
+	}
+}
+
+func TestCoalescingCancelsAbandonedCall(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	c, requests := blockingServer(t, release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.get(ctx, "/zones.json", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+
+	// the abandoned flight is forgotten, so a new caller sends a new request
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel2()
+	c.get(ctx2, "/zones.json", nil)
+	if n := atomic.LoadInt32(requests); n != 2 {
+		t.Errorf("%d requests sent, want 2", n)
+	}
+}
+
+func TestFlightGroupPanic(t *testing.T) {
+	g := &flightGroup{calls: map[string]*flight{}}
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	recovered := make(chan interface{}, 2)
+	call := func() {
+		defer func() { recovered <- recover() }()
+		g.do(context.Background(), "key", func(context.Context) ([]byte, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}
+	go call()
+	<-started
+	go func() {
+		defer func() { recovered <- recover() }()
+		g.do(context.Background(), "key", func(context.Context) ([]byte, error) {
+			t.Error("second call was not coalesced")
+			return nil, nil
+		})
+	}()
+	waitForWaiters(t, g, 2)
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-recovered:
+			if r != "boom" {
+				t.Errorf("caller recovered %v, want boom", r)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("caller deadlocked after a panic")
+		}
+	}
+}
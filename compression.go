@@ -0,0 +1,45 @@
+package keycdn
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+)
+
+// WithRequestCompression gzips request bodies of at least minSize bytes,
+// e.g. large URL lists of purge requests. Response compression is always
+// negotiated and does not need to be enabled.
+func WithRequestCompression(minSize int) Option {
+	return func(c *Client) {
+		c.compressMin = minSize
+	}
+}
+
+// compressBody gzips the body if request compression is enabled and the
+// body is large enough. It returns the (possibly) compressed body and
+// whether it was compressed.
+func (c *Client) compressBody(b []byte) ([]byte, bool, error) {
+	if c.compressMin <= 0 || len(b) < c.compressMin {
+		return b, false, nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, false, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, err
+	}
+	return buf.Bytes(), true, nil
+}
+
+// decompressedBody returns a reader for the decoded response body. The
+// client requests gzip explicitly so that it works with any round tripper,
+// which disables the transparent decompression of http.Transport.
+func decompressedBody(resp *http.Response) (io.Reader, error) {
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		return resp.Body, nil
+	}
+	return gzip.NewReader(resp.Body)
+}
@@ -0,0 +1,93 @@
+package keycdn
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseDecompression(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := zoneBody
+		if r.URL.Path == "/zones.json" {
+			body = `{"status":"success","data":{"zones":[{"id":"1","name":"assets"}]}}`
+		}
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			io.WriteString(w, body)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		io.WriteString(zw, body)
+		zw.Close()
+	}))
+	defer srv.Close()
+	c, err := New("key", WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := c.Zone(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z.Name != "assets" {
+		t.Errorf("Zone = %+v, want zone assets", z)
+	}
+	zones, err := c.Zones(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zones[1].Name != "assets" {
+		t.Errorf("Zones = %+v, want zone assets", zones)
+	}
+}
+
+func TestRequestCompression(t *testing.T) {
+	c, ts := newTestServer(t, `{"status":"success"}`, WithRequestCompression(256))
+	ts.route(http.MethodGet, "/zones.json", `{"status":"success","data":{"zones":[{"id":"1","name":"assets"}]}}`)
+
+	small := []string{"https://cdn.example.com/a.css"}
+	if err := c.PurgeZoneURL(context.Background(), 1, small); err != nil {
+		t.Fatal(err)
+	}
+	req := ts.last(t)
+	if enc := req.Header.Get("Content-Encoding"); enc != "" {
+		t.Errorf("Content-Encoding of a small body = %q, want none", enc)
+	}
+	if want := `{"urls":["https://cdn.example.com/a.css"]}`; req.Body != want {
+		t.Errorf("body = %s, want %s", req.Body, want)
+	}
+
+	var large []string
+	for i := 0; i < 20; i++ {
+		large = append(large, fmt.Sprintf("https://cdn.example.com/img/%d.jpg", i))
+	}
+	if err := c.PurgeZoneURL(context.Background(), 1, large); err != nil {
+		t.Fatal(err)
+	}
+	req = ts.last(t)
+	if enc := req.Header.Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", enc)
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	zr, err := gzip.NewReader(bytes.NewBufferString(req.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got URLs
+	if err := json.NewDecoder(zr).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.URLs) != len(large) || got.URLs[19] != large[19] {
+		t.Errorf("decompressed URLs = %v, want %v", got.URLs, large)
+	}
+}
@@ -0,0 +1,195 @@
+package keycdn
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+
+	"github.com/dominikschulz/keycdn/v2/internal/zonewire"
+)
+
+// ZoneConfig is the serializable configuration of a zone. Unlike Zone it
+// carries stable JSON tags which are independent of the KeyCDN wire names,
+// so it can be stored in version control and fed back into ApplyZone via
+// ZoneConfig.Zone. Note that it includes the secure token key and the custom
+// SSL key if the zone has them. Settings which are nil are left alone by
+// ZoneConfig.Diff and PlanSync.
+type ZoneConfig struct {
+	ID                      uint64     `json:"id,omitempty"`
+	Name                    string     `json:"name"`
+	Status                  ZoneStatus `json:"status,omitempty"`
+	Type                    ZoneType   `json:"type"`
+	ForceDownload           *bool      `json:"force_download,omitempty"`
+	CORS                    *bool      `json:"cors,omitempty"`
+	Gzip                    *bool      `json:"gzip,omitempty"`
+	Expire                  int        `json:"expire"`
+	HTTP2                   *bool      `json:"http2,omitempty"`
+	SecureToken             *bool      `json:"secure_token,omitempty"`
+	SecureTokenKey          string     `json:"secure_token_key,omitempty"`
+	SSLCert                 string     `json:"ssl_cert,omitempty"`
+	CustomSSLKey            string     `json:"custom_ssl_key,omitempty"`
+	CustomSSLCert           string     `json:"custom_ssl_cert,omitempty"`
+	ForceSSL                *bool      `json:"force_ssl,omitempty"`
+	OriginURL               string     `json:"origin_url,omitempty"`
+	CacheMaxExpire          int        `json:"cache_max_expire"`
+	CacheIgnoreCacheControl *bool      `json:"cache_ignore_cache_control,omitempty"`
+	CacheIgnoreQueryString  *bool      `json:"cache_ignore_query_string,omitempty"`
+	CacheStripCookies       *bool      `json:"cache_strip_cookies,omitempty"`
+	CachePullKey            string     `json:"cache_pull_key,omitempty"`
+	CacheCanonical          *bool      `json:"cache_canonical,omitempty"`
+	CacheRobots             *bool      `json:"cache_robots,omitempty"`
+	CacheHostHeader         *bool      `json:"cache_host_header,omitempty"`
+	OriginHostHeader        string     `json:"origin_host_header,omitempty"`
+	OriginShield            *bool      `json:"origin_shield,omitempty"`
+	ImageProcessing         *bool      `json:"image_processing,omitempty"`
+	WebP                    *bool      `json:"webp,omitempty"`
+}
+
+// Config returns the serializable configuration of the zone
+func (z Zone) Config() ZoneConfig {
+	return ZoneConfig{
+		ID:                      z.ID,
+		Name:                    z.Name,
+		Status:                  z.Status,
+		Type:                    z.Type,
+		ForceDownload:           Bool(z.ForceDownload),
+		CORS:                    Bool(z.CORS),
+		Gzip:                    Bool(z.Gzip),
+		Expire:                  z.Expire,
+		HTTP2:                   Bool(z.HTTP2),
+		SecureToken:             Bool(z.SecureToken),
+		SecureTokenKey:          z.SecureTokenKey,
+		SSLCert:                 z.SSLCert,
+		CustomSSLKey:            z.CustomSSLKey,
+		CustomSSLCert:           *z.customSSLCert(),
+		ForceSSL:                Bool(z.ForceSSL),
+		OriginURL:               z.OriginURL,
+		CacheMaxExpire:          z.CacheMaxExpire,
+		CacheIgnoreCacheControl: Bool(z.CacheIgnoreCacheControl),
+		CacheIgnoreQueryString:  Bool(z.CacheIgnoreQueryString),
+		CacheStripCookies:       Bool(z.CacheStripCookies),
+		CachePullKey:            z.CachePullKey,
+		CacheCanonical:          Bool(z.CacheCanonical),
+		CacheRobots:             Bool(z.CacheRobots),
+		CacheHostHeader:         Bool(z.CacheHostHeader),
+		OriginHostHeader:        z.OriginHostHeader,
+		OriginShield:            Bool(z.OriginShield),
+		ImageProcessing:         Bool(z.ImageProcessing),
+		WebP:                    Bool(z.WebP),
+	}
+}
+
+// Zone converts the configuration back into a Zone, e.g. to pass it to
+// ApplyZone. Unset settings are disabled.
+func (zc ZoneConfig) Zone() Zone {
+	return Zone{
+		ID:                      zc.ID,
+		Name:                    zc.Name,
+		Status:                  zc.Status,
+		Type:                    zc.Type,
+		ForceDownload:           deref(zc.ForceDownload),
+		CORS:                    deref(zc.CORS),
+		Gzip:                    deref(zc.Gzip),
+		Expire:                  zc.Expire,
+		HTTP2:                   deref(zc.HTTP2),
+		SecureToken:             deref(zc.SecureToken),
+		SecureTokenKey:          zc.SecureTokenKey,
+		SSLCert:                 zc.SSLCert,
+		CustomSSLKey:            zc.CustomSSLKey,
+		CustomSSLCert:           zc.CustomSSLCert,
+		CunstomSSLCert:          zc.CustomSSLCert,
+		ForceSSL:                deref(zc.ForceSSL),
+		OriginURL:               zc.OriginURL,
+		CacheMaxExpire:          zc.CacheMaxExpire,
+		CacheIgnoreCacheControl: deref(zc.CacheIgnoreCacheControl),
+		CacheIgnoreQueryString:  deref(zc.CacheIgnoreQueryString),
+		CacheStripCookies:       deref(zc.CacheStripCookies),
+		CachePullKey:            zc.CachePullKey,
+		CacheCanonical:          deref(zc.CacheCanonical),
+		CacheRobots:             deref(zc.CacheRobots),
+		CacheHostHeader:         deref(zc.CacheHostHeader),
+		OriginHostHeader:        zc.OriginHostHeader,
+		OriginShield:            deref(zc.OriginShield),
+		ImageProcessing:         deref(zc.ImageProcessing),
+		WebP:                    deref(zc.WebP),
+	}
+}
+
+// values encodes the set fields of the configuration as form parameters.
+// Unlike for a Zone, explicitly disabled settings are included.
+func (zc ZoneConfig) values() url.Values {
+	vs := zoneValues(zc.Zone())
+	rv := reflect.ValueOf(zc)
+	for _, f := range zonewire.Fields {
+		if f.Kind != zonewire.Flag {
+			continue
+		}
+		b, _ := rv.FieldByName(f.Name).Interface().(*bool)
+		switch {
+		case b == nil:
+		case *b:
+			vs.Set(f.Param, zonewire.Enabled)
+		default:
+			vs.Set(f.Param, zonewire.Disabled)
+		}
+	}
+	return vs
+}
+
+// Diff returns the settings of the configuration which differ from actual.
+// Unset settings, empty strings and zero numbers are treated as "don't
+// care", but unlike Diff explicitly disabled settings are compared.
+func (zc ZoneConfig) Diff(actual Zone) ZoneDiff {
+	return diffValues(zc.values(), actual)
+}
+
+// CreateRequest returns a request which creates a zone with the
+// configuration. Unset settings use the API defaults.
+func (zc ZoneConfig) CreateRequest() ZoneCreateRequest {
+	r := ZoneCreateRequest{
+		Name:                    zc.Name,
+		Type:                    zc.Type,
+		OriginURL:               zc.OriginURL,
+		OriginHostHeader:        zc.OriginHostHeader,
+		CachePullKey:            zc.CachePullKey,
+		SecureTokenKey:          zc.SecureTokenKey,
+		SSLCert:                 zc.SSLCert,
+		CustomSSLCert:           zc.CustomSSLCert,
+		CustomSSLKey:            zc.CustomSSLKey,
+		ForceDownload:           zc.ForceDownload,
+		CORS:                    zc.CORS,
+		Gzip:                    zc.Gzip,
+		HTTP2:                   zc.HTTP2,
+		ForceSSL:                zc.ForceSSL,
+		SecureToken:             zc.SecureToken,
+		OriginShield:            zc.OriginShield,
+		CacheIgnoreCacheControl: zc.CacheIgnoreCacheControl,
+		CacheIgnoreQueryString:  zc.CacheIgnoreQueryString,
+		CacheStripCookies:       zc.CacheStripCookies,
+		CacheCanonical:          zc.CacheCanonical,
+		CacheRobots:             zc.CacheRobots,
+		CacheHostHeader:         zc.CacheHostHeader,
+		ImageProcessing:         zc.ImageProcessing,
+		WebP:                    zc.WebP,
+	}
+	if zc.Expire != 0 {
+		r.Expire = Int(zc.Expire)
+	}
+	if zc.CacheMaxExpire != 0 {
+		r.CacheMaxExpire = Int(zc.CacheMaxExpire)
+	}
+	return r
+}
+
+func deref(b *bool) bool {
+	return b != nil && *b
+}
+
+// ZoneConfig returns the serializable configuration of a zone
+func (c *Client) ZoneConfig(ctx context.Context, zoneID uint64) (ZoneConfig, error) {
+	z, err := c.Zone(ctx, zoneID)
+	if err != nil {
+		return ZoneConfig{}, err
+	}
+	return z.Config(), nil
+}
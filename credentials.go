@@ -0,0 +1,138 @@
+package keycdn
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// EnvAPIKey is the environment variable read by EnvCredentials
+const EnvAPIKey = "KEYCDN_API_KEY"
+
+// CredentialProvider supplies the API key. It is asked for the key before
+// every request, so providers backed by a secret store should cache it.
+type CredentialProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// CredentialProviderFunc adapts a function to the CredentialProvider
+// interface
+type CredentialProviderFunc func(ctx context.Context) (string, error)
+
+// APIKey implements CredentialProvider
+func (f CredentialProviderFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticCredentials always returns the given key
+func StaticCredentials(key string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (string, error) {
+		return key, nil
+	})
+}
+
+// EnvCredentials reads the key from the KEYCDN_API_KEY environment variable
+func EnvCredentials() CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (string, error) {
+		key := os.Getenv(EnvAPIKey)
+		if key == "" {
+			return "", fmt.Errorf("%s is not set", EnvAPIKey)
+		}
+		return key, nil
+	})
+}
+
+// FileCredentials reads the key from a file containing only the key.
+// Surrounding whitespace is ignored. The file is read for every request, so
+// a rotated key is picked up without restarting.
+func FileCredentials(path string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (string, error) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		key := strings.TrimSpace(string(b))
+		if key == "" {
+			return "", fmt.Errorf("no API key in %s", path)
+		}
+		return key, nil
+	})
+}
+
+// WithCredentials obtains the API key from p instead of using the key passed
+// to New, which may be empty in that case
+func WithCredentials(p CredentialProvider) Option {
+	return func(c *Client) {
+		c.credentials = p
+	}
+}
+
+// apiKey returns the key for the next request
+func (c *Client) apiKey(ctx context.Context) (string, error) {
+	if c.credentials == nil {
+		return c.apikey.get(), nil
+	}
+	return c.credentials.APIKey(ctx)
+}
+
+// keyHolder stores the API key passed to New. It is shared by all copies of
+// a Client so the key can be rotated while requests are in flight.
+type keyHolder struct {
+	mu  sync.RWMutex
+	key string
+}
+
+func (h *keyHolder) get() string {
+	if h == nil {
+		return ""
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.key
+}
+
+func (h *keyHolder) set(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.key = key
+}
+
+// SetAPIKey replaces the API key of the client and all its copies. Requests
+// already sent keep using the old key. It has no effect on clients using
+// WithCredentials, rotate the key in the provider instead.
+func (c *Client) SetAPIKey(key string) {
+	c.apikey.set(key)
+}
+
+// AuthMode selects how the API key is sent
+type AuthMode int
+
+const (
+	// AuthBasic sends the key as the user name of HTTP basic auth, which
+	// is the default
+	AuthBasic AuthMode = iota
+	// AuthBearer sends the key as a bearer token in the Authorization
+	// header
+	AuthBearer
+)
+
+// WithAuthMode selects how the API key is sent to the API
+func WithAuthMode(m AuthMode) Option {
+	return func(c *Client) {
+		c.authMode = m
+	}
+}
+
+// authenticate adds the API key to req
+func (c *Client) authenticate(req *http.Request, key string) {
+	switch c.authMode {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+key)
+	default:
+		req.SetBasicAuth(key, "")
+	}
+}
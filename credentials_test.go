@@ -0,0 +1,57 @@
+package keycdn
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// authOf returns the Authorization header of the last request and the basic
+// auth credentials parsed from it
+func authOf(t *testing.T, ts *testServer) (header, user, password string, basic bool) {
+	t.Helper()
+	h := ts.last(t).Header
+	user, password, basic = (&http.Request{Header: h}).BasicAuth()
+	return h.Get("Authorization"), user, password, basic
+}
+
+func TestAuthBasicIsDefault(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody)
+	if _, err := c.Zone(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, user, password, basic := authOf(t, ts); !basic || user != "key" || password != "" {
+		t.Errorf("basic auth = %q:%q (%t), want the key as user without password", user, password, basic)
+	}
+}
+
+func TestAuthBearer(t *testing.T) {
+	ctx := context.Background()
+	c, ts := newTestServer(t, zoneBody, WithAuthMode(AuthBearer))
+	if _, err := c.Zone(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	header, _, _, basic := authOf(t, ts)
+	if header != "Bearer key" || basic {
+		t.Errorf("Authorization = %q, want the key as bearer token", header)
+	}
+
+	// a rotated key is sent as bearer token as well
+	c.SetAPIKey("rotated")
+	if _, err := c.Zone(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if header, _, _, _ := authOf(t, ts); header != "Bearer rotated" {
+		t.Errorf("Authorization after SetAPIKey = %q, want Bearer rotated", header)
+	}
+}
+
+func TestAuthBearerWithCredentials(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody, WithAuthMode(AuthBearer), WithCredentials(StaticCredentials("from-provider")))
+	if _, err := c.Zone(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if header, _, _, _ := authOf(t, ts); header != "Bearer from-provider" {
+		t.Errorf("Authorization = %q, want the key of the provider as bearer token", header)
+	}
+}
@@ -0,0 +1,43 @@
+package keycdn
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// maxDebugBody limits how much of a request or response body is logged
+const maxDebugBody = 1024
+
+// debugf logs a message if a debug logger is configured. The API key and
+// the basic auth credentials derived from it are redacted from the message.
+// Bodies are redacted before they are truncated so that no partial key is
+// logged.
+func (c *Client) debugf(key, format string, args ...interface{}) {
+	if c.debug == nil {
+		return
+	}
+	for i, a := range args {
+		switch v := a.(type) {
+		case string:
+			args[i] = redact(v, key)
+		case []byte:
+			args[i] = truncate(redact(string(v), key), maxDebugBody)
+		}
+	}
+	c.debug(format, args...)
+}
+
+func redact(s, key string) string {
+	if key == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, base64.StdEncoding.EncodeToString([]byte(key+":")), "REDACTED")
+	return strings.ReplaceAll(s, key, "REDACTED")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,56 @@
+package keycdn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugRedaction(t *testing.T) {
+	const key = "sk_0123456789abcdef"
+	for name, mode := range map[string]AuthMode{"basic": AuthBasic, "bearer": AuthBearer} {
+		t.Run(name, func(t *testing.T) {
+			var auth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+				if r.URL.Path == "/zones.json" {
+					io.WriteString(w, `{"status":"success","data":{"zones":[{"id":"1","name":"assets"}]}}`)
+					return
+				}
+				// an error page echoing the request, with the key
+				// straddling the truncation limit
+				body := `{"status":"error","description":"` + auth + " "
+				body += strings.Repeat("x", maxDebugBody-len(body)-8) + key + `"}`
+				io.WriteString(w, body)
+			}))
+			defer srv.Close()
+			var out strings.Builder
+			c, err := New(key, WithBaseURL(srv.URL), WithAuthMode(mode), WithDebugLogger(func(format string, args ...interface{}) {
+				fmt.Fprintf(&out, format+"\n", args...)
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.PurgeZoneURL(context.Background(), 1, []string{"https://cdn.example.com/?key=" + key}); err == nil {
+				t.Fatal("PurgeZoneURL succeeded, want the error of the server")
+			}
+			logged := out.String()
+			if !strings.Contains(logged, "DELETE "+srv.URL+"/zones/purgeurl/1.json") {
+				t.Fatalf("purge request not logged:\n%s", logged)
+			}
+			if !strings.Contains(logged, "REDACTED") {
+				t.Errorf("debug output does not mark the redaction:\n%s", logged)
+			}
+			for _, secret := range []string{key, key[:8], auth, strings.Fields(auth)[1]} {
+				if strings.Contains(logged, secret) {
+					t.Errorf("debug output contains %q:\n%s", secret, logged)
+				}
+			}
+		})
+	}
+}
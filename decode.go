@@ -0,0 +1,133 @@
+package keycdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithStrictDecoding makes responses containing fields the client does not
+// know fail to decode. If onUnknown is not nil the first unknown field of a
+// response is reported to it instead and the response is decoded as usual,
+// which helps to notice API additions without breaking callers. Responses are
+// always buffered in strict mode.
+func WithStrictDecoding(onUnknown func(file, field string)) Option {
+	return func(c *Client) {
+		c.strict = true
+		c.onUnknownField = onUnknown
+	}
+}
+
+// unmarshal decodes the response b of file into v
+func (c *Client) unmarshal(file string, b []byte, v interface{}) error {
+	if !c.strict {
+		return json.Unmarshal(b, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	field, unknown := unknownField(err)
+	if !unknown || c.onUnknownField == nil {
+		return err
+	}
+	c.onUnknownField(file, field)
+	// start over, v may have been filled partially
+	rv := reflect.ValueOf(v).Elem()
+	rv.Set(reflect.Zero(rv.Type()))
+	return json.Unmarshal(b, v)
+}
+
+// unknownField extracts the field name from the error encoding/json returns
+// for unknown fields
+func unknownField(err error) (string, bool) {
+	const prefix = "json: unknown field "
+	if err == nil || !strings.HasPrefix(err.Error(), prefix) {
+		return "", false
+	}
+	field, uerr := strconv.Unquote(strings.TrimPrefix(err.Error(), prefix))
+	if uerr != nil {
+		return "", false
+	}
+	return field, true
+}
+
+// errNotInData is returned if the payload of a response is missing
+var errNotInData = errors.New("not found in data")
+
+// dataResponse is the envelope of most responses, the payload is stored
+// under a key of the data object
+type dataResponse[T any] struct {
+	response
+	Data map[string]T `json:"data"`
+}
+
+// checkStatus fails with an *APIError for op if resp does not report success
+// and passes its description to the Warn hook otherwise
+func (c *Client) checkStatus(file string, resp response, op string) error {
+	if resp.Status != "success" {
+		return statusError(file, resp, "%s", op)
+	}
+	c.warn(file, resp.Description)
+	return nil
+}
+
+// checkResponse decodes the response b of file, which has no payload, and
+// checks its status
+func (c *Client) checkResponse(file string, b []byte, op string) error {
+	var resp response
+	if err := c.unmarshal(file, b, &resp); err != nil {
+		return err
+	}
+	return c.checkStatus(file, resp, op)
+}
+
+// decodeData decodes the response b of file, checks its status and returns
+// the payload stored under key
+func decodeData[T any](c *Client, file string, b []byte, key, op string) (T, error) {
+	var dr dataResponse[T]
+	var v T
+	if err := c.unmarshal(file, b, &dr); err != nil {
+		return v, err
+	}
+	if err := c.checkStatus(file, dr.response, op); err != nil {
+		return v, err
+	}
+	v, found := dr.Data[key]
+	if !found {
+		return v, fmt.Errorf("%s %w", key, errNotInData)
+	}
+	return v, nil
+}
+
+// GetJSON sends a GET request to path and decodes the whole response into a
+// value of type T. Like Client.Do it gives access to endpoints without
+// dedicated methods, but decodes into a custom type:
+//
+//	type aliasResponse struct {
+//		Data struct {
+//			Aliases []map[string]string `json:"zonealiases"`
+//		} `json:"data"`
+//	}
+//	resp, err := keycdn.GetJSON[aliasResponse](ctx, c, "/zonealiases.json", nil)
+//
+// Responses reporting a status other than success fail with an *APIError.
+func GetJSON[T any](ctx context.Context, c *Client, path string, args map[string]string) (T, error) {
+	var v T
+	b, err := c.get(ctx, path, args)
+	if err != nil {
+		return v, err
+	}
+	var resp response
+	if err := json.Unmarshal(b, &resp); err == nil && resp.Status != "" {
+		if err := c.checkStatus(path, resp, "Failed to get "+path); err != nil {
+			return v, err
+		}
+	}
+	err = c.unmarshal(path, b, &v)
+	return v, err
+}
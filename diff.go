@@ -0,0 +1,86 @@
+package keycdn
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// sensitiveParams are zone settings whose values are hidden by
+// ZoneDiff.String
+var sensitiveParams = map[string]bool{
+	"customsslkey":   true,
+	"securetokenkey": true,
+	"cachepullkey":   true,
+}
+
+// FieldChange is a zone setting which differs between two zones. Values are
+// in the representation of the API, e.g. "enabled" for booleans.
+type FieldChange struct {
+	// Param is the API parameter, e.g. "originurl"
+	Param string
+	Old   string
+	New   string
+}
+
+// ZoneDiff lists the changed settings of a zone in a stable order
+type ZoneDiff []FieldChange
+
+// Diff returns the settings of desired which differ from actual. Empty
+// strings, zero numbers and disabled settings in desired are treated as
+// "don't care", like by ApplyZone, so Diff never disables a setting. IDs
+// are not compared. Use ZoneConfig.Diff to compare disabled settings too.
+func Diff(desired, actual Zone) ZoneDiff {
+	return diffValues(zoneValues(desired), actual)
+}
+
+// diffValues returns the settings in desired, given as form parameters,
+// which differ from actual
+func diffValues(desired url.Values, actual Zone) ZoneDiff {
+	var d ZoneDiff
+	for _, f := range zoneFields {
+		if _, set := desired[f.Param]; !set {
+			continue
+		}
+		if v, old := desired.Get(f.Param), f.format(&actual); v != old {
+			d = append(d, FieldChange{Param: f.Param, Old: old, New: v})
+		}
+	}
+	return d
+}
+
+// Update returns a ZoneUpdate of the given zone which applies the changes
+func (d ZoneDiff) Update(zoneID uint64) *ZoneUpdate {
+	u := NewZoneUpdate(zoneID)
+	u.values = d.values()
+	return u
+}
+
+func (d ZoneDiff) values() url.Values {
+	vs := url.Values{}
+	for _, c := range d {
+		vs.Set(c.Param, c.New)
+	}
+	return vs
+}
+
+// String formats the changes one per line, e.g. for a plan. The values of
+// keys are masked.
+func (d ZoneDiff) String() string {
+	var sb strings.Builder
+	for _, c := range d {
+		old, v := c.Old, c.New
+		if sensitiveParams[c.Param] {
+			old, v = mask(old), mask(v)
+		}
+		fmt.Fprintf(&sb, "%s: %q -> %q\n", c.Param, old, v)
+	}
+	return sb.String()
+}
+
+func mask(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "***"
+}
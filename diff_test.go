@@ -0,0 +1,72 @@
+package keycdn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	actual := Zone{
+		ID:        1,
+		Name:      "assets",
+		OriginURL: "https://example.com",
+		Expire:    60,
+		CORS:      true,
+		HTTP2:     true,
+	}
+	for _, tc := range []struct {
+		name    string
+		desired Zone
+		want    ZoneDiff
+	}{
+		{
+			name:    "zero values are don't care",
+			desired: Zone{Name: "assets"},
+		},
+		{
+			name:    "false does not disable",
+			desired: Zone{Name: "assets", CORS: false, HTTP2: false},
+		},
+		{
+			name:    "ID is not compared",
+			desired: Zone{ID: 2, Name: "assets"},
+		},
+		{
+			name:    "changed string and number",
+			desired: Zone{OriginURL: "https://example.org", Expire: 120},
+			want: ZoneDiff{
+				{Param: "expire", Old: "60", New: "120"},
+				{Param: "originurl", Old: "https://example.com", New: "https://example.org"},
+			},
+		},
+		{
+			name:    "enabled setting",
+			desired: Zone{Gzip: true, CORS: true},
+			want:    ZoneDiff{{Param: "gzip", Old: "disabled", New: "enabled"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Diff(tc.desired, actual); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Diff() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestZoneDiffUpdate(t *testing.T) {
+	d := Diff(Zone{Gzip: true, Expire: 5}, Zone{})
+	u := d.Update(7)
+	if u.ZoneID() != 7 {
+		t.Errorf("ZoneID() = %d, want 7", u.ZoneID())
+	}
+	if got, want := u.Patch(Zone{}), (Zone{Gzip: true, Expire: 5}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Patch() = %+v, want %+v", got, want)
+	}
+}
+
+func TestZoneDiffStringMasksKeys(t *testing.T) {
+	d := Diff(Zone{SecureTokenKey: "secret"}, Zone{})
+	if got, want := d.String(), "securetokenkey: \"\" -> \"***\"\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package keycdn
+
+import (
+	"net/url"
+	"strings"
+)
+
+// dryRunBody is returned instead of sending mutating requests in dry-run
+// mode
+var dryRunBody = []byte(`{"status":"success","description":"dry run","data":{}}`)
+
+// WithDryRun makes all mutating calls, i.e. purges and the creation,
+// modification and deletion of zones and edge rules, log the request with
+// logf instead of sending it and report success. Read-only calls are sent as
+// usual. logf may be nil to discard the log.
+func WithDryRun(logf func(format string, args ...interface{})) Option {
+	return func(c *Client) {
+		c.dryRun = true
+		c.dryRunLog = logf
+	}
+}
+
+// mutating returns true if the request changes state on the API. Purging a
+// whole zone uses GET.
+func mutating(method, file string) bool {
+	return method != "GET" || strings.HasPrefix(file, "/zones/purge/")
+}
+
+// dryRunZone returns the zone that a create or edit request would result in
+// as far as it is known from the request
+func dryRunZone(zoneID uint64, vs url.Values) Zone {
+	zr := make(zoneResp, len(vs))
+	for k := range vs {
+		zr[k] = vs.Get(k)
+	}
+	z := zr.ToZone()
+	z.ID = zoneID
+	return z
+}
@@ -0,0 +1,98 @@
+package keycdn
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// EdgeRule is a rule evaluated on the edge servers of a zone, e.g. to add a
+// header or to redirect matching requests
+type EdgeRule struct {
+	ID      uint64
+	ZoneID  uint64
+	Name    string
+	Type    string
+	Matcher string
+	Action  string
+}
+
+type edgeRuleResp map[string]string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *edgeRuleResp) UnmarshalJSON(b []byte) error {
+	m, err := unmarshalFlexMap(b)
+	*r = m
+	return err
+}
+
+// ToEdgeRule converts an edge rule response to a proper EdgeRule object
+func (r edgeRuleResp) ToEdgeRule() EdgeRule {
+	rule := EdgeRule{
+		Name:    r["name"],
+		Type:    r["type"],
+		Matcher: r["matcher"],
+		Action:  r["action"],
+	}
+	if id, err := strconv.ParseUint(r["id"], 10, 64); err == nil {
+		rule.ID = id
+	}
+	if id, err := strconv.ParseUint(r["zone_id"], 10, 64); err == nil {
+		rule.ZoneID = id
+	}
+	return rule
+}
+
+// ZoneEdgeRules returns the edge rules of a zone
+func (c *Client) ZoneEdgeRules(ctx context.Context, zoneID uint64) ([]EdgeRule, error) {
+	var rules []EdgeRule
+	it := c.EdgeRuleIterator(ctx, zoneID)
+	for it.Next() {
+		rules = append(rules, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to list edge rules of Zone %d: %w", zoneID, err)
+	}
+	return rules, nil
+}
+
+// EdgeRuleIterator iterates over the edge rules of a zone
+func (c *Client) EdgeRuleIterator(ctx context.Context, zoneID uint64) *Iterator[EdgeRule] {
+	args := map[string]string{"zone_id": strconv.FormatUint(zoneID, 10)}
+	return listIterator(ctx, c, "/edgerules.json", args, "edgerules", edgeRuleResp.ToEdgeRule)
+}
+
+// CreateEdgeRule adds a new edge rule to a zone and returns it
+func (c *Client) CreateEdgeRule(ctx context.Context, zoneID uint64, rule EdgeRule) (EdgeRule, error) {
+	vs := url.Values{}
+	vs.Set("zone_id", strconv.FormatUint(zoneID, 10))
+	vs.Set("name", rule.Name)
+	vs.Set("type", rule.Type)
+	vs.Set("matcher", rule.Matcher)
+	vs.Set("action", rule.Action)
+	b, err := c.post(ctx, "/edgerules.json", vs, encodingForm)
+	if err != nil {
+		return EdgeRule{}, err
+	}
+	if c.dryRun {
+		rule.ZoneID = zoneID
+		return rule, nil
+	}
+	op := fmt.Sprintf("Failed to create edge rule for Zone %d", zoneID)
+	r, err := decodeData[edgeRuleResp](c, "/edgerules.json", b, "edgerule", op)
+	if err != nil {
+		return EdgeRule{}, err
+	}
+	return r.ToEdgeRule(), nil
+}
+
+// DeleteEdgeRule removes an edge rule
+func (c *Client) DeleteEdgeRule(ctx context.Context, id uint64) error {
+	file := "/edgerules/" + strconv.FormatUint(id, 10) + ".json"
+	b, err := c.delete(ctx, file, nil)
+	if err != nil {
+		return err
+	}
+	return c.checkResponse(file, b, fmt.Sprintf("Failed to delete edge rule %d", id))
+}
@@ -0,0 +1,194 @@
+package keycdn
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNoData is returned by the report methods if the API returned a
+	// valid but empty result, i.e. there was no traffic in the interval.
+	// The accompanying result is zero.
+	ErrNoData = errors.New("no data in the requested interval")
+	// ErrStatsMissing is returned by the report methods if the response
+	// did not contain any stats at all, which usually indicates an API
+	// problem rather than a lack of traffic
+	ErrStatsMissing = errors.New("stats not found in data")
+	// ErrUnauthorized is returned if the API rejected the API key, e.g.
+	// because it was revoked or rotated
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound is returned if the requested object does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrZoneNotFound is returned if the requested zone does not exist. Such
+	// errors match ErrNotFound as well.
+	ErrZoneNotFound error = &notFoundError{"zone not found"}
+	// ErrRateLimited is returned if the API rejected a request because of
+	// its rate limits, even after retrying
+	ErrRateLimited = errors.New("rate limited")
+	// ErrBadRequest is returned if the API rejected the parameters of a
+	// request
+	ErrBadRequest = errors.New("bad request")
+	// ErrCircuitOpen is returned without contacting the API while the
+	// circuit breaker is open, see WithCircuitBreaker
+	ErrCircuitOpen = errors.New("circuit breaker open")
+	// ErrQuotaExceeded is returned by the AccountLimits checks if the
+	// account has no room for more zones or zone aliases
+	ErrQuotaExceeded = errors.New("zone quota exceeded")
+	// ErrIncompleteChain is returned by SetZoneCertificate if the
+	// certificate chain lacks an intermediate certificate, which would
+	// break clients that don't have it cached
+	ErrIncompleteChain = errors.New("incomplete certificate chain")
+)
+
+// notFoundError is a sentinel for a missing object of a specific kind. It
+// unwraps to ErrNotFound, so wrapping it matches both sentinels.
+type notFoundError struct {
+	msg string
+}
+
+func (e *notFoundError) Error() string {
+	return e.msg
+}
+
+func (e *notFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// ErrorCode is a stable, machine-readable classification of an API error
+type ErrorCode string
+
+// Known error codes. The API reports errors only as free text, the codes are
+// derived from it on a best-effort basis.
+const (
+	ErrorCodeUnknown           ErrorCode = ""
+	ErrorCodeUnauthorized      ErrorCode = "unauthorized"
+	ErrorCodeNotFound          ErrorCode = "not_found"
+	ErrorCodeRateLimited       ErrorCode = "rate_limited"
+	ErrorCodeInvalidParameters ErrorCode = "invalid_parameters"
+	ErrorCodeQuotaExceeded     ErrorCode = "quota_exceeded"
+)
+
+// errorCodePatterns maps fragments of descriptions to error codes. The
+// first match wins.
+var errorCodePatterns = []struct {
+	fragment string
+	code     ErrorCode
+}{
+	{"unauthorized", ErrorCodeUnauthorized},
+	{"invalid api key", ErrorCodeUnauthorized},
+	{"authentication failed", ErrorCodeUnauthorized},
+	{"not found", ErrorCodeNotFound},
+	{"does not exist", ErrorCodeNotFound},
+	{"too many requests", ErrorCodeRateLimited},
+	{"rate limit", ErrorCodeRateLimited},
+	{"invalid param", ErrorCodeInvalidParameters},
+	{"is invalid", ErrorCodeInvalidParameters},
+	{"quota", ErrorCodeQuotaExceeded},
+	{"limit reached", ErrorCodeQuotaExceeded},
+}
+
+// parseErrorCode derives the error code from an API description
+func parseErrorCode(description string) ErrorCode {
+	desc := strings.ToLower(description)
+	for _, p := range errorCodePatterns {
+		if strings.Contains(desc, p.fragment) {
+			return p.code
+		}
+	}
+	return ErrorCodeUnknown
+}
+
+// APIError is returned if the API did not report success
+type APIError struct {
+	// Op describes the failed operation, e.g. "Failed to purge Zone 1"
+	Op string
+	// Endpoint is the path of the API endpoint, e.g. "/zones/purge/1.json"
+	Endpoint string
+	// StatusCode is the HTTP status code of the response. It is 0 if the
+	// API reported the error within a successful HTTP response.
+	StatusCode int
+	// Status is the status field of the response, usually "error"
+	Status string
+	// Code is derived from the description on a best-effort basis
+	Code ErrorCode
+	// Description is the raw message returned by the API
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return e.Op + ": " + e.Description
+}
+
+// Is allows matching API errors with the sentinel errors based on their
+// code, e.g. errors.Is(err, ErrZoneNotFound)
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Code == ErrorCodeUnauthorized
+	case ErrNotFound:
+		return e.Code == ErrorCodeNotFound
+	case ErrZoneNotFound:
+		return e.Code == ErrorCodeNotFound && strings.HasPrefix(e.Endpoint, "/zones/")
+	case ErrRateLimited:
+		return e.Code == ErrorCodeRateLimited
+	case ErrBadRequest:
+		return e.Code == ErrorCodeInvalidParameters
+	}
+	return false
+}
+
+// maxErrorBody limits how much of a non-JSON error body ends up in an error
+const maxErrorBody = 256
+
+// httpError returns the error for a response with a 4xx or 5xx status. The
+// description is taken from the JSON error payload if there is one and from
+// the raw body otherwise.
+func httpError(method, file string, statusCode int, body []byte) error {
+	e := &APIError{
+		Op:         fmt.Sprintf("%s %s (HTTP %d)", method, file, statusCode),
+		Endpoint:   file,
+		StatusCode: statusCode,
+	}
+	var resp response
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Description != "" {
+		e.Status = string(resp.Status)
+		e.Description = resp.Description
+	} else {
+		e.Description = strings.TrimSpace(string(body))
+		if len(e.Description) > maxErrorBody {
+			e.Description = e.Description[:maxErrorBody] + "..."
+		}
+		if e.Description == "" {
+			e.Description = http.StatusText(statusCode)
+		}
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		e.Code = ErrorCodeUnauthorized
+	case http.StatusNotFound:
+		e.Code = ErrorCodeNotFound
+	case http.StatusTooManyRequests:
+		e.Code = ErrorCodeRateLimited
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		e.Code = ErrorCodeInvalidParameters
+	default:
+		e.Code = parseErrorCode(e.Description)
+	}
+	return e
+}
+
+// statusError returns the error for a response from file which did not
+// report success
+func statusError(file string, resp response, format string, args ...interface{}) error {
+	return &APIError{
+		Op:          fmt.Sprintf(format, args...),
+		Endpoint:    file,
+		Status:      string(resp.Status),
+		Code:        parseErrorCode(resp.Description),
+		Description: resp.Description,
+	}
+}
@@ -0,0 +1,49 @@
+package keycdn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestZoneWithoutDataIsZoneNotFound(t *testing.T) {
+	c, _ := newTestServer(t, `{"status":"success","description":"","data":{}}`)
+
+	_, err := c.Zone(context.Background(), 1)
+	if !errors.Is(err, ErrZoneNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want it to match ErrZoneNotFound and ErrNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "Zone 1") {
+		t.Errorf("err = %q, want it to name the zone", err)
+	}
+}
+
+func TestZoneNotFoundIsNotFound(t *testing.T) {
+	if !errors.Is(ErrZoneNotFound, ErrNotFound) {
+		t.Error("ErrZoneNotFound does not match ErrNotFound")
+	}
+	if errors.Is(ErrNotFound, ErrZoneNotFound) {
+		t.Error("ErrNotFound matches ErrZoneNotFound")
+	}
+	wrapped := fmt.Errorf("Failed to get Zone 1: %w", ErrZoneNotFound)
+	if !errors.Is(wrapped, ErrZoneNotFound) || !errors.Is(wrapped, ErrNotFound) {
+		t.Errorf("wrapped ErrZoneNotFound %v does not match ErrZoneNotFound and ErrNotFound", wrapped)
+	}
+}
+
+func TestAPIErrorNotFoundDependsOnEndpoint(t *testing.T) {
+	zoneErr := &APIError{Endpoint: "/zones/1.json", Code: ErrorCodeNotFound}
+	if !errors.Is(zoneErr, ErrZoneNotFound) || !errors.Is(zoneErr, ErrNotFound) {
+		t.Errorf("not found error of a zone endpoint %+v does not match ErrZoneNotFound and ErrNotFound", zoneErr)
+	}
+
+	aliasErr := &APIError{Endpoint: "/zonealiases/1.json", Code: ErrorCodeNotFound}
+	if errors.Is(aliasErr, ErrZoneNotFound) {
+		t.Errorf("not found error of a zone alias %+v matches ErrZoneNotFound", aliasErr)
+	}
+	if !errors.Is(aliasErr, ErrNotFound) {
+		t.Errorf("not found error of a zone alias %+v does not match ErrNotFound", aliasErr)
+	}
+}
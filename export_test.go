@@ -0,0 +1,27 @@
+package keycdn
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+// TrafficTime exposes the timestamp fallback of traffic amounts
+func TrafficTime(timestamp string, clock Clock) time.Time {
+	return trafficAmountResp{Timestamp: flexString(timestamp)}.Time(clock)
+}
+
+// SelfSignedCert returns a PEM encoded self-signed certificate for host and
+// its key
+func SelfSignedCert(t *testing.T, host string, notBefore, notAfter time.Time) (certPEM, keyPEM string) {
+	c := newTestCert(t, host, notBefore, notAfter, nil, false)
+	return c.certPEM, c.keyPEM
+}
+
+// SetEdgeProbes replaces the DNS lookup and the certificate fetch used by
+// EnableLetsEncrypt
+func SetEdgeProbes(c *Client, lookupCNAME func(ctx context.Context, host string) (string, error), servedCertificate func(ctx context.Context, host string) (*x509.Certificate, error)) {
+	c.lookupCNAME = lookupCNAME
+	c.servedCertificate = servedCertificate
+}
@@ -0,0 +1,60 @@
+package keycdn
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// flexString is a value which the API sends as a string, a number or a
+// boolean depending on the endpoint
+type flexString string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *flexString) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		*s = flexString(str)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err == nil {
+		*s = flexString(n)
+		return nil
+	}
+	var v bool
+	if err := json.Unmarshal(b, &v); err == nil {
+		*s = flexString(strconv.FormatBool(v))
+		return nil
+	}
+	return fmt.Errorf("expected a string, number or boolean: %s", b)
+}
+
+// unmarshalFlexMap decodes an object whose values may be strings, numbers or
+// booleans into a map of strings
+func unmarshalFlexMap(b []byte) (map[string]string, error) {
+	var fm map[string]flexString
+	if err := json.Unmarshal(b, &fm); err != nil {
+		return nil, err
+	}
+	if fm == nil {
+		return nil, nil
+	}
+	m := make(map[string]string, len(fm))
+	for k, v := range fm {
+		m[k] = string(v)
+	}
+	return m, nil
+}
+
+// parseAmount parses a counter, which the API sends as an integer or
+// sometimes as a float. The bool is false if s is not a valid amount.
+func parseAmount(s string) (uint64, bool) {
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f >= 0 {
+		return uint64(f), true
+	}
+	return 0, false
+}
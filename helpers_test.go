@@ -0,0 +1,94 @@
+package keycdn
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// capturedRequest is a request received by a test server
+type capturedRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   string
+	Form   url.Values
+}
+
+// testServer answers every request with body, unless another body is
+// routed to its method and path, and records the requests
+type testServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []capturedRequest
+	routes   map[string]string
+}
+
+func newTestServer(t *testing.T, body string, opts ...Option) (*Client, *testServer) {
+	t.Helper()
+	ts := &testServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(b))
+		ts.mu.Lock()
+		ts.requests = append(ts.requests, capturedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Header: r.Header.Clone(),
+			Body:   string(b),
+			Form:   form,
+		})
+		resp, found := ts.routes[r.Method+" "+r.URL.Path]
+		ts.mu.Unlock()
+		if !found {
+			resp = body
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, resp)
+	}))
+	t.Cleanup(ts.Close)
+	c, err := New("key", append([]Option{WithBaseURL(ts.URL)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, ts
+}
+
+// route answers requests with the method and path with body instead
+func (ts *testServer) route(method, path, body string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.routes == nil {
+		ts.routes = make(map[string]string)
+	}
+	ts.routes[method+" "+path] = body
+}
+
+// last returns the last received request
+func (ts *testServer) last(t *testing.T) capturedRequest {
+	t.Helper()
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if len(ts.requests) == 0 {
+		t.Fatal("no request received")
+	}
+	return ts.requests[len(ts.requests)-1]
+}
+
+// count returns the number of received requests with the method
+func (ts *testServer) count(method string) int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	n := 0
+	for _, r := range ts.requests {
+		if r.Method == method {
+			n++
+		}
+	}
+	return n
+}
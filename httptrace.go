@@ -0,0 +1,124 @@
+package keycdn
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// WithClientTrace attaches the hooks returned by newTrace to every request,
+// including retries, e.g. to log DNS lookups and connection reuse. newTrace
+// is called with the method and endpoint of each request.
+func WithClientTrace(newTrace func(method, endpoint string) *httptrace.ClientTrace) Option {
+	return func(c *Client) {
+		c.clientTrace = newTrace
+	}
+}
+
+// RequestTimings are the durations of the phases of a single request.
+// Phases which did not happen, e.g. the DNS lookup and connect on a reused
+// connection, are zero.
+type RequestTimings struct {
+	Method   string
+	Endpoint string
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	// TTFB is the time from sending the request until the first response
+	// byte arrived
+	TTFB time.Duration
+	// Total is the time until the response headers were read
+	Total      time.Duration
+	ReusedConn bool
+}
+
+// WithRequestTimings reports the phase timings of every request, including
+// retries, to fn. It helps to tell slow DNS, connects or TLS handshakes from
+// a slow API.
+func WithRequestTimings(fn func(RequestTimings)) Option {
+	return func(c *Client) {
+		c.timings = fn
+	}
+}
+
+// traceRequest attaches the configured traces to req. The returned function
+// must be called once the response headers were read.
+func (c *Client) traceRequest(req *http.Request, method, file string) (*http.Request, func()) {
+	ctx := req.Context()
+	if c.clientTrace != nil {
+		if t := c.clientTrace(method, file); t != nil {
+			ctx = httptrace.WithClientTrace(ctx, t)
+		}
+	}
+	if c.timings == nil {
+		return req.WithContext(ctx), func() {}
+	}
+
+	var mu sync.Mutex
+	start := c.clock.Now()
+	t := RequestTimings{Method: method, Endpoint: file}
+	var dnsStart, connectStart, tlsStart, wrote time.Time
+	since := func(from time.Time) time.Duration {
+		if from.IsZero() {
+			return 0
+		}
+		return c.clock.Now().Sub(from)
+	}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			dnsStart = c.clock.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			t.DNS = since(dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			mu.Lock()
+			defer mu.Unlock()
+			connectStart = c.clock.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			t.Connect = since(connectStart)
+		},
+		TLSHandshakeStart: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			tlsStart = c.clock.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			t.TLS = since(tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			t.ReusedConn = info.Reused
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			wrote = c.clock.Now()
+		},
+		GotFirstResponseByte: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			t.TTFB = since(wrote)
+		},
+	}
+	ctx = httptrace.WithClientTrace(ctx, trace)
+	return req.WithContext(ctx), func() {
+		mu.Lock()
+		t.Total = c.clock.Now().Sub(start)
+		timings := t
+		mu.Unlock()
+		c.timings(timings)
+	}
+}
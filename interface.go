@@ -0,0 +1,79 @@
+package keycdn
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// API is the set of operations provided by Client. Code depending on API
+// instead of Client can be tested with a fake implementation, e.g. the one
+// in the keycdntest package.
+type API interface {
+	// Zones
+	Zones(ctx context.Context) (map[uint64]Zone, error)
+	ZoneIterator(ctx context.Context) *Iterator[Zone]
+	ZonesFiltered(ctx context.Context, filter ZoneFilter) (map[uint64]Zone, error)
+	Zone(ctx context.Context, zoneID uint64) (Zone, error)
+	ZoneByName(ctx context.Context, name string) (Zone, error)
+	ZoneConfig(ctx context.Context, zoneID uint64) (ZoneConfig, error)
+	ZoneNameIndex(ctx context.Context) (map[string]uint64, error)
+	ZoneSSLStatus(ctx context.Context, zoneID uint64) (SSLStatus, error)
+	SetZoneCertificate(ctx context.Context, zoneID uint64, certPEM, keyPEM string) (Zone, error)
+	EnableLetsEncrypt(ctx context.Context, zoneID uint64) (Zone, error)
+	ZoneSecureToken(ctx context.Context, zoneID uint64) (SecureTokenConfig, error)
+	EnableSecureToken(ctx context.Context, zoneID uint64, key string) (Zone, error)
+	DisableSecureToken(ctx context.Context, zoneID uint64) (Zone, error)
+	SetSecureTokenKey(ctx context.Context, zoneID uint64, key string) (Zone, error)
+	AddZone(ctx context.Context, r ZoneCreateRequest) (Zone, error)
+	CreateZoneIfNotExists(ctx context.Context, z Zone) (Zone, bool, error)
+	EditZone(ctx context.Context, z Zone) (Zone, error)
+	UpdateZone(ctx context.Context, u *ZoneUpdate) (Zone, error)
+	DeleteZone(ctx context.Context, zoneID uint64) error
+	ApplyZone(ctx context.Context, desired Zone) (Zone, error)
+	CloneZone(ctx context.Context, sourceZoneID uint64, newName string, opts CloneOptions) (Zone, error)
+	WaitForZoneActive(ctx context.Context, zoneID uint64, pollInterval time.Duration) error
+	WaitForZoneActiveFunc(ctx context.Context, zoneID uint64, pollInterval time.Duration, fn func(ZoneStatus)) error
+
+	// Edge rules
+	ZoneEdgeRules(ctx context.Context, zoneID uint64) ([]EdgeRule, error)
+	EdgeRuleIterator(ctx context.Context, zoneID uint64) *Iterator[EdgeRule]
+	CreateEdgeRule(ctx context.Context, zoneID uint64, rule EdgeRule) (EdgeRule, error)
+	DeleteEdgeRule(ctx context.Context, id uint64) error
+
+	// Zone aliases
+	ZoneAliases(ctx context.Context) ([]ZoneAlias, error)
+	AddZoneAlias(ctx context.Context, zoneID uint64, name string) (ZoneAlias, error)
+	DeleteZoneAlias(ctx context.Context, id uint64) error
+
+	// Zone referrers
+	ZoneReferrers(ctx context.Context) ([]ZoneReferrer, error)
+	AddZoneReferrer(ctx context.Context, zoneID uint64, name string) (ZoneReferrer, error)
+	DeleteZoneReferrer(ctx context.Context, id uint64) error
+	SetZoneReferrers(ctx context.Context, zoneID uint64, names []string) ([]ZoneReferrer, error)
+
+	// Purging
+	PurgeZoneCache(ctx context.Context, zoneID uint64) error
+	PurgeZoneURL(ctx context.Context, zoneID uint64, urls []string) error
+	PurgeChangedURLs(ctx context.Context, zoneID uint64, urls map[string]time.Time, since time.Time) ([]string, error)
+	PurgeZonePrefix(ctx context.Context, zoneID uint64, prefixes []string) error
+	PurgeZoneTag(ctx context.Context, zoneID uint64, tags []string) error
+
+	// Reports
+	Traffic(ctx context.Context, zoneID uint64, from, to time.Time) (uint64, error)
+	ProcessedImages(ctx context.Context, zoneID uint64, from, to time.Time) (uint64, error)
+	Stats(ctx context.Context, zoneID uint64, from, to time.Time) (map[string]uint64, error)
+	StatsMulti(ctx context.Context, zoneIDs []uint64, from, to time.Time) (map[uint64]map[string]uint64, error)
+	StatsSummary(ctx context.Context, zoneID uint64, from, to time.Time) (StatsSummary, error)
+	CacheHitRatio(ctx context.Context, zoneID uint64, from, to time.Time) (float64, error)
+	TopURLs(ctx context.Context, zoneID uint64, from, to time.Time, limit int) ([]URLStat, error)
+	Usage(ctx context.Context, from, to time.Time) (Usage, error)
+
+	// Account
+	AccountLimits(ctx context.Context) (AccountLimits, error)
+
+	// Other endpoints
+	Do(ctx context.Context, method, path string, query url.Values, body interface{}) ([]byte, *ResponseMeta, error)
+}
+
+var _ API = (*Client)(nil)
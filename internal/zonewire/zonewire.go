@@ -0,0 +1,63 @@
+// Package zonewire maps the settings of keycdn.Zone to the parameters of the
+// KeyCDN API. It is shared by the client and the fake server of the
+// keycdntest package so both agree on the wire names.
+package zonewire
+
+// Kind is the type of a zone setting
+type Kind int
+
+// Kinds of zone settings
+const (
+	// String settings are sent as is
+	String Kind = iota
+	// Flag settings are sent as Enabled or Disabled
+	Flag
+	// Number settings are sent as decimal integers
+	Number
+)
+
+// Wire values of Flag settings
+const (
+	Enabled  = "enabled"
+	Disabled = "disabled"
+)
+
+// Field is a settable zone field
+type Field struct {
+	// Param is the name of the API parameter
+	Param string
+	// Name is the name of the field in keycdn.Zone
+	Name string
+	Kind Kind
+}
+
+// Fields lists all settable zone fields
+var Fields = []Field{
+	{Param: "name", Name: "Name", Kind: String},
+	{Param: "status", Name: "Status", Kind: String},
+	{Param: "type", Name: "Type", Kind: String},
+	{Param: "forcedownload", Name: "ForceDownload", Kind: Flag},
+	{Param: "cors", Name: "CORS", Kind: Flag},
+	{Param: "gzip", Name: "Gzip", Kind: Flag},
+	{Param: "expire", Name: "Expire", Kind: Number},
+	{Param: "http2", Name: "HTTP2", Kind: Flag},
+	{Param: "securetoken", Name: "SecureToken", Kind: Flag},
+	{Param: "securetokenkey", Name: "SecureTokenKey", Kind: String},
+	{Param: "sslcert", Name: "SSLCert", Kind: String},
+	{Param: "customsslkey", Name: "CustomSSLKey", Kind: String},
+	{Param: "customsslcert", Name: "CustomSSLCert", Kind: String},
+	{Param: "forcessl", Name: "ForceSSL", Kind: Flag},
+	{Param: "originurl", Name: "OriginURL", Kind: String},
+	{Param: "cachemaxexpire", Name: "CacheMaxExpire", Kind: Number},
+	{Param: "cacheignorecachecontrol", Name: "CacheIgnoreCacheControl", Kind: Flag},
+	{Param: "cacheignorequerystring", Name: "CacheIgnoreQueryString", Kind: Flag},
+	{Param: "cachestripcookies", Name: "CacheStripCookies", Kind: Flag},
+	{Param: "cachepullkey", Name: "CachePullKey", Kind: String},
+	{Param: "cachecanonical", Name: "CacheCanonical", Kind: Flag},
+	{Param: "cacherobots", Name: "CacheRobots", Kind: Flag},
+	{Param: "cachehostheader", Name: "CacheHostHeader", Kind: Flag},
+	{Param: "originhostheader", Name: "OriginHostHeader", Kind: String},
+	{Param: "originshield", Name: "OriginShield", Kind: Flag},
+	{Param: "imageprocessing", Name: "ImageProcessing", Kind: Flag},
+	{Param: "webp", Name: "WebP", Kind: Flag},
+}
@@ -0,0 +1,97 @@
+package keycdntest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+)
+
+var _ keycdn.Clock = (*Clock)(nil)
+
+// Clock is a settable keycdn.Clock, see keycdn.WithClock. Its time only
+// moves when Set or Advance is called, or on every After call if
+// AutoAdvance is enabled. It is safe for concurrent use.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	auto   bool
+	waits  []time.Duration
+	timers []timer
+}
+
+type timer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewClock returns a clock set to now
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now implements keycdn.Clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After implements keycdn.Clock. The channel fires once the clock reaches
+// the current time plus d. The requested duration is recorded, see Waits.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, timer{at: c.now.Add(d), ch: ch})
+	if c.auto && d > 0 {
+		c.now = c.now.Add(d)
+	}
+	c.fire()
+	return ch
+}
+
+// Set moves the clock to t and fires the timers which are due
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+	c.fire()
+}
+
+// Advance moves the clock forward by d and fires the timers which are due
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// AutoAdvance makes every call of After move the clock forward by the
+// requested duration, so waiting code proceeds immediately
+func (c *Clock) AutoAdvance(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auto = enabled
+}
+
+// Waits returns the durations passed to After in order
+func (c *Clock) Waits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.waits...)
+}
+
+// fire sends the current time on the due timers, c.mu must be held
+func (c *Clock) fire() {
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = pending
+}
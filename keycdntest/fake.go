@@ -0,0 +1,1036 @@
+package keycdntest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+)
+
+// Call is a recorded invocation of a Fake method
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Purge is a recorded purge request. Kind is one of "cache", "url",
+// "prefix" and "tag", Items holds the purged URLs, prefixes or tags.
+type Purge struct {
+	ZoneID uint64
+	Kind   string
+	Items  []string
+}
+
+// Fake is an in-memory implementation of keycdn.API. It keeps zones and
+// edge rules in memory, answers reports from seeded data and records all
+// invocations:
+//
+//	f := keycdntest.NewFake()
+//	f.SeedZone(keycdn.Zone{ID: 1, Name: "assets"})
+//	runCode(f)
+//	if got := f.PurgedURLs(1); ... {
+//	}
+//
+// The zero value is not usable, use NewFake.
+type Fake struct {
+	mu        sync.Mutex
+	nextID    uint64
+	zones     map[uint64]keycdn.Zone
+	edgeRules map[uint64][]keycdn.EdgeRule
+	aliases   []keycdn.ZoneAlias
+	referrers []keycdn.ZoneReferrer
+	stats     map[uint64]map[string]uint64
+	traffic   map[uint64]uint64
+	images    map[uint64]uint64
+	topURLs   map[uint64][]keycdn.URLStat
+	usage     *keycdn.Usage
+	limits    keycdn.AccountLimits
+	errs      map[string]error
+	calls     []Call
+	purges    []Purge
+}
+
+var _ keycdn.API = (*Fake)(nil)
+
+// NewFake returns an empty fake
+func NewFake() *Fake {
+	return &Fake{
+		nextID:    1,
+		zones:     make(map[uint64]keycdn.Zone),
+		edgeRules: make(map[uint64][]keycdn.EdgeRule),
+		stats:     make(map[uint64]map[string]uint64),
+		traffic:   make(map[uint64]uint64),
+		images:    make(map[uint64]uint64),
+		topURLs:   make(map[uint64][]keycdn.URLStat),
+		errs:      make(map[string]error),
+	}
+}
+
+// SeedZone adds a zone. A zone without ID gets the next free one, a zone
+// without status is active.
+func (f *Fake) SeedZone(z keycdn.Zone) keycdn.Zone {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.addZone(z)
+}
+
+func (f *Fake) addZone(z keycdn.Zone) keycdn.Zone {
+	if z.ID == 0 {
+		z.ID = f.nextID
+	}
+	if z.ID >= f.nextID {
+		f.nextID = z.ID + 1
+	}
+	if z.Status == "" {
+		z.Status = keycdn.ZoneStatusActive
+	}
+	f.zones[z.ID] = z
+	return z
+}
+
+// SetStats seeds the result of Stats for a zone
+func (f *Fake) SetStats(zoneID uint64, stats map[string]uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stats[zoneID] = stats
+}
+
+// SetTraffic seeds the result of Traffic for a zone
+func (f *Fake) SetTraffic(zoneID uint64, traffic uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.traffic[zoneID] = traffic
+}
+
+// SetProcessedImages seeds the result of ProcessedImages for a zone
+func (f *Fake) SetProcessedImages(zoneID uint64, images uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.images[zoneID] = images
+}
+
+// SetTopURLs seeds the result of TopURLs for a zone
+func (f *Fake) SetTopURLs(zoneID uint64, stats []keycdn.URLStat) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.topURLs[zoneID] = stats
+}
+
+// SetUsage seeds the result of Usage
+func (f *Fake) SetUsage(u keycdn.Usage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.usage = &u
+}
+
+// SetLimits seeds the quota reported by AccountLimits. The usage is
+// computed from the zones and aliases of the fake.
+func (f *Fake) SetLimits(zones, zoneAliases int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.limits = keycdn.AccountLimits{Zones: zones, ZoneAliases: zoneAliases}
+}
+
+// SetError makes the given method, e.g. "PurgeZoneURL", fail with err. A nil
+// err removes the failure.
+func (f *Fake) SetError(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errs, method)
+		return
+	}
+	f.errs[method] = err
+}
+
+// Calls returns all recorded invocations in order
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Purges returns all recorded purges in order
+func (f *Fake) Purges() []Purge {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Purge(nil), f.purges...)
+}
+
+// PurgedURLs returns the URLs purged from a zone in order
+func (f *Fake) PurgedURLs(zoneID uint64) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var urls []string
+	for _, p := range f.purges {
+		if p.ZoneID == zoneID && p.Kind == "url" {
+			urls = append(urls, p.Items...)
+		}
+	}
+	return urls
+}
+
+// call records an invocation and returns the configured error of method.
+// f.mu must be held.
+func (f *Fake) call(method string, args ...interface{}) error {
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+	return f.errs[method]
+}
+
+func notFound(zoneID uint64) error {
+	return &keycdn.APIError{
+		Op:          fmt.Sprintf("Failed to get Zone %d", zoneID),
+		Endpoint:    "/zones/" + strconv.FormatUint(zoneID, 10) + ".json",
+		StatusCode:  404,
+		Status:      "error",
+		Code:        keycdn.ErrorCodeNotFound,
+		Description: "Zone not found",
+	}
+}
+
+// deleteNotFound is the error the Client reports when deleting the
+// nonexistent object id of the given kind, e.g. "zone alias", from endpoint
+func deleteNotFound(endpoint, kind string, id uint64) error {
+	file := "/" + endpoint + "/" + strconv.FormatUint(id, 10) + ".json"
+	return &keycdn.APIError{
+		Op:          "DELETE " + file + " (HTTP 404)",
+		Endpoint:    file,
+		StatusCode:  404,
+		Status:      "error",
+		Code:        keycdn.ErrorCodeNotFound,
+		Description: strings.ToUpper(kind[:1]) + kind[1:] + " not found",
+	}
+}
+
+// Zones implements keycdn.API
+func (f *Fake) Zones(ctx context.Context) (map[uint64]keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	zones := make(map[uint64]keycdn.Zone, len(f.zones))
+	if err := f.call("Zones"); err != nil {
+		return zones, err
+	}
+	for id, z := range f.zones {
+		zones[id] = z
+	}
+	return zones, nil
+}
+
+// ZoneIterator implements keycdn.API. Zones are returned ordered by ID.
+func (f *Fake) ZoneIterator(ctx context.Context) *keycdn.Iterator[keycdn.Zone] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ZoneIterator"); err != nil {
+		return keycdn.NewIterator[keycdn.Zone](nil, err)
+	}
+	return keycdn.NewIterator(f.sortedZones(), nil)
+}
+
+func (f *Fake) sortedZones() []keycdn.Zone {
+	zones := make([]keycdn.Zone, 0, len(f.zones))
+	for _, z := range f.zones {
+		zones = append(zones, z)
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].ID < zones[j].ID
+	})
+	return zones
+}
+
+// ZonesFiltered implements keycdn.API
+func (f *Fake) ZonesFiltered(ctx context.Context, filter keycdn.ZoneFilter) (map[uint64]keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	zones := make(map[uint64]keycdn.Zone, len(f.zones))
+	if err := f.call("ZonesFiltered", filter); err != nil {
+		return zones, err
+	}
+	for id, z := range f.zones {
+		if filter.Match(z) {
+			zones[id] = z
+		}
+	}
+	return zones, nil
+}
+
+// Zone implements keycdn.API
+func (f *Fake) Zone(ctx context.Context, zoneID uint64) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Zone", zoneID); err != nil {
+		return keycdn.Zone{}, err
+	}
+	z, found := f.zones[zoneID]
+	if !found {
+		return keycdn.Zone{}, notFound(zoneID)
+	}
+	return z, nil
+}
+
+// ZoneByName implements keycdn.API. Like the Client it fails if the name
+// is used by more than one zone.
+func (f *Fake) ZoneByName(ctx context.Context, name string) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ZoneByName", name); err != nil {
+		return keycdn.Zone{}, err
+	}
+	z, found, err := f.zoneByName(name)
+	if err != nil {
+		return keycdn.Zone{}, err
+	}
+	if !found {
+		return keycdn.Zone{}, fmt.Errorf("Failed to get Zone %q: %w", name, keycdn.ErrZoneNotFound)
+	}
+	return z, nil
+}
+
+// ZoneConfig implements keycdn.API
+func (f *Fake) ZoneConfig(ctx context.Context, zoneID uint64) (keycdn.ZoneConfig, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ZoneConfig", zoneID); err != nil {
+		return keycdn.ZoneConfig{}, err
+	}
+	z, found := f.zones[zoneID]
+	if !found {
+		return keycdn.ZoneConfig{}, notFound(zoneID)
+	}
+	return z.Config(), nil
+}
+
+// ZoneNameIndex implements keycdn.API. Like the Client it fails if a name
+// is used by more than one zone.
+func (f *Fake) ZoneNameIndex(ctx context.Context) (map[string]uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ZoneNameIndex"); err != nil {
+		return nil, err
+	}
+	idx := make(map[string]uint64, len(f.zones))
+	for id, z := range f.zones {
+		if _, found := idx[z.Name]; found {
+			return nil, fmt.Errorf("multiple zones named %q", z.Name)
+		}
+		idx[z.Name] = id
+	}
+	return idx, nil
+}
+
+// ZoneSSLStatus implements keycdn.API. The expiry date of custom
+// certificates is not reported.
+func (f *Fake) ZoneSSLStatus(ctx context.Context, zoneID uint64) (keycdn.SSLStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ZoneSSLStatus", zoneID); err != nil {
+		return keycdn.SSLStatus{}, err
+	}
+	z, found := f.zones[zoneID]
+	if !found {
+		return keycdn.SSLStatus{}, notFound(zoneID)
+	}
+	return keycdn.SSLStatus{Type: z.SSLCert, ForceSSL: z.ForceSSL}, nil
+}
+
+// SetZoneCertificate implements keycdn.API. The certificate is stored
+// without validation.
+func (f *Fake) SetZoneCertificate(ctx context.Context, zoneID uint64, certPEM, keyPEM string) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("SetZoneCertificate", zoneID, certPEM, keyPEM); err != nil {
+		return keycdn.Zone{}, err
+	}
+	z, found := f.zones[zoneID]
+	if !found {
+		return keycdn.Zone{}, notFound(zoneID)
+	}
+	z.SSLCert = keycdn.SSLCertCustom
+	z.CustomSSLCert = certPEM
+	z.CustomSSLKey = keyPEM
+	f.zones[zoneID] = z
+	return z, nil
+}
+
+// EnableLetsEncrypt implements keycdn.API. The certificate is issued right
+// away, neither the aliases nor DNS are checked.
+func (f *Fake) EnableLetsEncrypt(ctx context.Context, zoneID uint64) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("EnableLetsEncrypt", zoneID); err != nil {
+		return keycdn.Zone{}, err
+	}
+	z, found := f.zones[zoneID]
+	if !found {
+		return keycdn.Zone{}, notFound(zoneID)
+	}
+	z.SSLCert = keycdn.SSLCertLetsEncrypt
+	f.zones[zoneID] = z
+	return z, nil
+}
+
+// ZoneSecureToken implements keycdn.API
+func (f *Fake) ZoneSecureToken(ctx context.Context, zoneID uint64) (keycdn.SecureTokenConfig, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ZoneSecureToken", zoneID); err != nil {
+		return keycdn.SecureTokenConfig{}, err
+	}
+	z, found := f.zones[zoneID]
+	if !found {
+		return keycdn.SecureTokenConfig{}, notFound(zoneID)
+	}
+	return keycdn.SecureTokenConfig{Enabled: z.SecureToken, Key: z.SecureTokenKey}, nil
+}
+
+// EnableSecureToken implements keycdn.API
+func (f *Fake) EnableSecureToken(ctx context.Context, zoneID uint64, key string) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("EnableSecureToken", zoneID, key); err != nil {
+		return keycdn.Zone{}, err
+	}
+	u := keycdn.NewZoneUpdate(zoneID).SetSecureToken(true)
+	if key != "" {
+		u.SetSecureTokenKey(key)
+	}
+	return f.updateZone(u)
+}
+
+// DisableSecureToken implements keycdn.API
+func (f *Fake) DisableSecureToken(ctx context.Context, zoneID uint64) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DisableSecureToken", zoneID); err != nil {
+		return keycdn.Zone{}, err
+	}
+	return f.updateZone(keycdn.NewZoneUpdate(zoneID).SetSecureToken(false))
+}
+
+// SetSecureTokenKey implements keycdn.API
+func (f *Fake) SetSecureTokenKey(ctx context.Context, zoneID uint64, key string) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("SetSecureTokenKey", zoneID, key); err != nil {
+		return keycdn.Zone{}, err
+	}
+	return f.updateZone(keycdn.NewZoneUpdate(zoneID).SetSecureTokenKey(key))
+}
+
+// AddZone implements keycdn.API. Invalid requests are rejected like by
+// the Client.
+func (f *Fake) AddZone(ctx context.Context, r keycdn.ZoneCreateRequest) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("AddZone", r); err != nil {
+		return keycdn.Zone{}, err
+	}
+	return f.createZone(r)
+}
+
+// createZone adds the zone described by r with a new ID. Invalid requests
+// are rejected like by the Client.
+func (f *Fake) createZone(r keycdn.ZoneCreateRequest) (keycdn.Zone, error) {
+	if err := r.Validate(); err != nil {
+		return keycdn.Zone{}, err
+	}
+	return f.addZone(r.Zone()), nil
+}
+
+// CreateZoneIfNotExists implements keycdn.API
+func (f *Fake) CreateZoneIfNotExists(ctx context.Context, z keycdn.Zone) (keycdn.Zone, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateZoneIfNotExists", z); err != nil {
+		return keycdn.Zone{}, false, err
+	}
+	existing, found, err := f.zoneByName(z.Name)
+	if err != nil {
+		return keycdn.Zone{}, false, err
+	}
+	if found {
+		return existing, false, nil
+	}
+	created, err := f.createZone(z.CreateRequest())
+	if err != nil {
+		return keycdn.Zone{}, false, err
+	}
+	return created, true, nil
+}
+
+// zoneByName looks up a zone by name. It fails if the name is ambiguous.
+func (f *Fake) zoneByName(name string) (keycdn.Zone, bool, error) {
+	var zone keycdn.Zone
+	found := false
+	for _, z := range f.zones {
+		if z.Name != name {
+			continue
+		}
+		if found {
+			return keycdn.Zone{}, false, fmt.Errorf("multiple zones named %q", name)
+		}
+		zone = z
+		found = true
+	}
+	return zone, found, nil
+}
+
+// EditZone implements keycdn.API. Like for the Client empty strings, zero
+// numbers and disabled settings are left unchanged.
+func (f *Fake) EditZone(ctx context.Context, z keycdn.Zone) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("EditZone", z); err != nil {
+		return keycdn.Zone{}, err
+	}
+	actual, found := f.zones[z.ID]
+	if !found {
+		return keycdn.Zone{}, notFound(z.ID)
+	}
+	return f.applyDiff(z, actual)
+}
+
+// applyDiff changes the settings of desired which differ from actual like
+// the Client does. Unset settings of desired are left unchanged.
+func (f *Fake) applyDiff(desired, actual keycdn.Zone) (keycdn.Zone, error) {
+	d := keycdn.Diff(desired, actual)
+	if len(d) == 0 {
+		return actual, nil
+	}
+	u := d.Update(actual.ID)
+	if err := u.Validate(); err != nil {
+		return keycdn.Zone{}, err
+	}
+	return f.updateZone(u)
+}
+
+// UpdateZone implements keycdn.API
+func (f *Fake) UpdateZone(ctx context.Context, u *keycdn.ZoneUpdate) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("UpdateZone", u); err != nil {
+		return keycdn.Zone{}, err
+	}
+	if err := u.Validate(); err != nil {
+		return keycdn.Zone{}, err
+	}
+	return f.updateZone(u)
+}
+
+func (f *Fake) updateZone(u *keycdn.ZoneUpdate) (keycdn.Zone, error) {
+	z, found := f.zones[u.ZoneID()]
+	if !found {
+		return keycdn.Zone{}, notFound(u.ZoneID())
+	}
+	return f.addZone(u.Patch(z)), nil
+}
+
+// DeleteZone implements keycdn.API. The edge rules of the zone are deleted
+// as well.
+func (f *Fake) DeleteZone(ctx context.Context, zoneID uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DeleteZone", zoneID); err != nil {
+		return err
+	}
+	if _, found := f.zones[zoneID]; !found {
+		return notFound(zoneID)
+	}
+	delete(f.zones, zoneID)
+	delete(f.edgeRules, zoneID)
+	return nil
+}
+
+// ApplyZone implements keycdn.API. Like for the Client only the settings
+// which differ are changed.
+func (f *Fake) ApplyZone(ctx context.Context, desired keycdn.Zone) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ApplyZone", desired); err != nil {
+		return keycdn.Zone{}, err
+	}
+	actual, found := f.zones[desired.ID]
+	if desired.ID == 0 {
+		var err error
+		actual, found, err = f.zoneByName(desired.Name)
+		if err != nil {
+			return keycdn.Zone{}, err
+		}
+		if !found {
+			return f.createZone(desired.CreateRequest())
+		}
+	} else if !found {
+		return keycdn.Zone{}, notFound(desired.ID)
+	}
+	return f.applyDiff(desired, actual)
+}
+
+// CloneZone implements keycdn.API
+func (f *Fake) CloneZone(ctx context.Context, sourceZoneID uint64, newName string, opts keycdn.CloneOptions) (keycdn.Zone, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CloneZone", sourceZoneID, newName, opts); err != nil {
+		return keycdn.Zone{}, err
+	}
+	src, found := f.zones[sourceZoneID]
+	if !found {
+		return keycdn.Zone{}, notFound(sourceZoneID)
+	}
+	z := src
+	z.ID = 0
+	z.Name = newName
+	z.Status = ""
+	clone := f.addZone(z)
+	for _, r := range f.referrers {
+		if opts.Referrers && r.ZoneID == sourceZoneID {
+			f.addZoneReferrer(clone.ID, r.Name)
+		}
+	}
+	for _, a := range f.aliases {
+		if opts.Aliases == nil || a.ZoneID != sourceZoneID {
+			continue
+		}
+		if name := opts.Aliases(a.Name); name != "" {
+			f.aliases = append(f.aliases, keycdn.ZoneAlias{ID: f.nextID, ZoneID: clone.ID, Name: name})
+			f.nextID++
+		}
+	}
+	return clone, nil
+}
+
+// WaitForZoneActive implements keycdn.API. Zones of the fake are active
+// right away unless seeded otherwise, in which case it fails immediately.
+func (f *Fake) WaitForZoneActive(ctx context.Context, zoneID uint64, pollInterval time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("WaitForZoneActive", zoneID, pollInterval); err != nil {
+		return err
+	}
+	z, found := f.zones[zoneID]
+	if !found {
+		return notFound(zoneID)
+	}
+	return zoneActive(z)
+}
+
+// WaitForZoneActiveFunc implements keycdn.API. fn is called once with the
+// current status of the zone.
+func (f *Fake) WaitForZoneActiveFunc(ctx context.Context, zoneID uint64, pollInterval time.Duration, fn func(keycdn.ZoneStatus)) error {
+	f.mu.Lock()
+	if err := f.call("WaitForZoneActiveFunc", zoneID, pollInterval); err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	z, found := f.zones[zoneID]
+	f.mu.Unlock()
+	if !found {
+		return notFound(zoneID)
+	}
+	if fn != nil {
+		fn(z.Status)
+	}
+	return zoneActive(z)
+}
+
+func zoneActive(z keycdn.Zone) error {
+	if z.Status != keycdn.ZoneStatusActive {
+		return fmt.Errorf("Zone %d is %s", z.ID, z.Status)
+	}
+	return nil
+}
+
+// ZoneEdgeRules implements keycdn.API
+func (f *Fake) ZoneEdgeRules(ctx context.Context, zoneID uint64) ([]keycdn.EdgeRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ZoneEdgeRules", zoneID); err != nil {
+		return nil, err
+	}
+	return append([]keycdn.EdgeRule(nil), f.edgeRules[zoneID]...), nil
+}
+
+// EdgeRuleIterator implements keycdn.API
+func (f *Fake) EdgeRuleIterator(ctx context.Context, zoneID uint64) *keycdn.Iterator[keycdn.EdgeRule] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("EdgeRuleIterator", zoneID); err != nil {
+		return keycdn.NewIterator[keycdn.EdgeRule](nil, err)
+	}
+	return keycdn.NewIterator(append([]keycdn.EdgeRule(nil), f.edgeRules[zoneID]...), nil)
+}
+
+// CreateEdgeRule implements keycdn.API
+func (f *Fake) CreateEdgeRule(ctx context.Context, zoneID uint64, rule keycdn.EdgeRule) (keycdn.EdgeRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateEdgeRule", zoneID, rule); err != nil {
+		return keycdn.EdgeRule{}, err
+	}
+	if _, found := f.zones[zoneID]; !found {
+		return keycdn.EdgeRule{}, notFound(zoneID)
+	}
+	rule.ID = f.nextID
+	rule.ZoneID = zoneID
+	f.nextID++
+	f.edgeRules[zoneID] = append(f.edgeRules[zoneID], rule)
+	return rule, nil
+}
+
+// DeleteEdgeRule implements keycdn.API
+func (f *Fake) DeleteEdgeRule(ctx context.Context, id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DeleteEdgeRule", id); err != nil {
+		return err
+	}
+	for zoneID, rules := range f.edgeRules {
+		for i, r := range rules {
+			if r.ID == id {
+				f.edgeRules[zoneID] = append(rules[:i:i], rules[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return deleteNotFound("edgerules", "edge rule", id)
+}
+
+// ZoneAliases implements keycdn.API
+func (f *Fake) ZoneAliases(ctx context.Context) ([]keycdn.ZoneAlias, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ZoneAliases"); err != nil {
+		return nil, err
+	}
+	return append([]keycdn.ZoneAlias(nil), f.aliases...), nil
+}
+
+// AddZoneAlias implements keycdn.API
+func (f *Fake) AddZoneAlias(ctx context.Context, zoneID uint64, name string) (keycdn.ZoneAlias, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("AddZoneAlias", zoneID, name); err != nil {
+		return keycdn.ZoneAlias{}, err
+	}
+	if _, found := f.zones[zoneID]; !found {
+		return keycdn.ZoneAlias{}, notFound(zoneID)
+	}
+	alias := keycdn.ZoneAlias{ID: f.nextID, ZoneID: zoneID, Name: name}
+	f.nextID++
+	f.aliases = append(f.aliases, alias)
+	return alias, nil
+}
+
+// DeleteZoneAlias implements keycdn.API
+func (f *Fake) DeleteZoneAlias(ctx context.Context, id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DeleteZoneAlias", id); err != nil {
+		return err
+	}
+	for i, a := range f.aliases {
+		if a.ID == id {
+			f.aliases = append(f.aliases[:i:i], f.aliases[i+1:]...)
+			return nil
+		}
+	}
+	return deleteNotFound("zonealiases", "zone alias", id)
+}
+
+// ZoneReferrers implements keycdn.API
+func (f *Fake) ZoneReferrers(ctx context.Context) ([]keycdn.ZoneReferrer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ZoneReferrers"); err != nil {
+		return nil, err
+	}
+	return append([]keycdn.ZoneReferrer(nil), f.referrers...), nil
+}
+
+// AddZoneReferrer implements keycdn.API
+func (f *Fake) AddZoneReferrer(ctx context.Context, zoneID uint64, name string) (keycdn.ZoneReferrer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("AddZoneReferrer", zoneID, name); err != nil {
+		return keycdn.ZoneReferrer{}, err
+	}
+	return f.addZoneReferrer(zoneID, name)
+}
+
+func (f *Fake) addZoneReferrer(zoneID uint64, name string) (keycdn.ZoneReferrer, error) {
+	if _, found := f.zones[zoneID]; !found {
+		return keycdn.ZoneReferrer{}, notFound(zoneID)
+	}
+	ref := keycdn.ZoneReferrer{ID: f.nextID, ZoneID: zoneID, Name: name}
+	f.nextID++
+	f.referrers = append(f.referrers, ref)
+	return ref, nil
+}
+
+// DeleteZoneReferrer implements keycdn.API
+func (f *Fake) DeleteZoneReferrer(ctx context.Context, id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DeleteZoneReferrer", id); err != nil {
+		return err
+	}
+	for i, r := range f.referrers {
+		if r.ID == id {
+			f.referrers = append(f.referrers[:i:i], f.referrers[i+1:]...)
+			return nil
+		}
+	}
+	return deleteNotFound("zonereferrers", "zone referrer", id)
+}
+
+// SetZoneReferrers implements keycdn.API. Like the Client it keeps
+// existing referrers and their IDs.
+func (f *Fake) SetZoneReferrers(ctx context.Context, zoneID uint64, names []string) ([]keycdn.ZoneReferrer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("SetZoneReferrers", zoneID, names); err != nil {
+		return nil, err
+	}
+	if _, found := f.zones[zoneID]; !found {
+		return nil, notFound(zoneID)
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	existing := make(map[string]keycdn.ZoneReferrer)
+	kept := f.referrers[:0:0]
+	for _, r := range f.referrers {
+		if r.ZoneID == zoneID {
+			if !wanted[r.Name] {
+				continue
+			}
+			existing[r.Name] = r
+		}
+		kept = append(kept, r)
+	}
+	f.referrers = kept
+
+	refs := make([]keycdn.ZoneReferrer, 0, len(names))
+	for _, name := range names {
+		if !wanted[name] {
+			continue
+		}
+		wanted[name] = false
+		ref, found := existing[name]
+		if !found {
+			ref, _ = f.addZoneReferrer(zoneID, name)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// purge records a purge of an existing zone
+func (f *Fake) purge(method string, zoneID uint64, kind string, items []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(method, zoneID, items); err != nil {
+		return err
+	}
+	if _, found := f.zones[zoneID]; !found {
+		return notFound(zoneID)
+	}
+	f.purges = append(f.purges, Purge{ZoneID: zoneID, Kind: kind, Items: append([]string(nil), items...)})
+	return nil
+}
+
+// PurgeZoneCache implements keycdn.API
+func (f *Fake) PurgeZoneCache(ctx context.Context, zoneID uint64) error {
+	return f.purge("PurgeZoneCache", zoneID, "cache", nil)
+}
+
+// PurgeZoneURL implements keycdn.API
+func (f *Fake) PurgeZoneURL(ctx context.Context, zoneID uint64, urls []string) error {
+	return f.purge("PurgeZoneURL", zoneID, "url", urls)
+}
+
+// PurgeChangedURLs implements keycdn.API
+func (f *Fake) PurgeChangedURLs(ctx context.Context, zoneID uint64, urls map[string]time.Time, since time.Time) ([]string, error) {
+	changed := make([]string, 0, len(urls))
+	for u, mtime := range urls {
+		if mtime.After(since) {
+			changed = append(changed, u)
+		}
+	}
+	if len(changed) == 0 {
+		return changed, nil
+	}
+	sort.Strings(changed)
+	if err := f.purge("PurgeChangedURLs", zoneID, "url", changed); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// PurgeZonePrefix implements keycdn.API. Like the Client it adds a missing
+// trailing "*" to the recorded prefixes.
+func (f *Fake) PurgeZonePrefix(ctx context.Context, zoneID uint64, prefixes []string) error {
+	wildcards := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if !strings.HasSuffix(prefix, "*") {
+			prefix += "*"
+		}
+		wildcards = append(wildcards, prefix)
+	}
+	return f.purge("PurgeZonePrefix", zoneID, "prefix", wildcards)
+}
+
+// PurgeZoneTag implements keycdn.API
+func (f *Fake) PurgeZoneTag(ctx context.Context, zoneID uint64, tags []string) error {
+	return f.purge("PurgeZoneTag", zoneID, "tag", tags)
+}
+
+// Traffic implements keycdn.API. It returns keycdn.ErrNoData unless traffic
+// was seeded for the zone.
+func (f *Fake) Traffic(ctx context.Context, zoneID uint64, from, to time.Time) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Traffic", zoneID, from, to); err != nil {
+		return 0, err
+	}
+	t, found := f.traffic[zoneID]
+	if !found {
+		return 0, keycdn.ErrNoData
+	}
+	return t, nil
+}
+
+// ProcessedImages implements keycdn.API. It returns keycdn.ErrNoData unless
+// the number of images was seeded for the zone.
+func (f *Fake) ProcessedImages(ctx context.Context, zoneID uint64, from, to time.Time) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ProcessedImages", zoneID, from, to); err != nil {
+		return 0, err
+	}
+	n, found := f.images[zoneID]
+	if !found {
+		return 0, keycdn.ErrNoData
+	}
+	return n, nil
+}
+
+// Stats implements keycdn.API. It returns keycdn.ErrNoData unless stats
+// were seeded for the zone.
+func (f *Fake) Stats(ctx context.Context, zoneID uint64, from, to time.Time) (map[string]uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Stats", zoneID, from, to); err != nil {
+		return map[string]uint64{}, err
+	}
+	return f.zoneStats(zoneID)
+}
+
+func (f *Fake) zoneStats(zoneID uint64) (map[string]uint64, error) {
+	ret := make(map[string]uint64, 4)
+	stats, found := f.stats[zoneID]
+	if !found {
+		return ret, keycdn.ErrNoData
+	}
+	for k, v := range stats {
+		ret[k] = v
+	}
+	return ret, nil
+}
+
+// StatsMulti implements keycdn.API
+func (f *Fake) StatsMulti(ctx context.Context, zoneIDs []uint64, from, to time.Time) (map[uint64]map[string]uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ret := make(map[uint64]map[string]uint64, len(zoneIDs))
+	if err := f.call("StatsMulti", zoneIDs, from, to); err != nil {
+		return ret, err
+	}
+	for _, id := range zoneIDs {
+		ret[id], _ = f.zoneStats(id)
+	}
+	return ret, nil
+}
+
+// StatsSummary implements keycdn.API
+func (f *Fake) StatsSummary(ctx context.Context, zoneID uint64, from, to time.Time) (keycdn.StatsSummary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("StatsSummary", zoneID, from, to); err != nil {
+		return keycdn.StatsSummary{}, err
+	}
+	stats, _ := f.zoneStats(zoneID)
+	return keycdn.SummarizeStats(stats), nil
+}
+
+// CacheHitRatio implements keycdn.API
+func (f *Fake) CacheHitRatio(ctx context.Context, zoneID uint64, from, to time.Time) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CacheHitRatio", zoneID, from, to); err != nil {
+		return 0, err
+	}
+	stats, _ := f.zoneStats(zoneID)
+	return keycdn.SummarizeStats(stats).HitRatio, nil
+}
+
+// TopURLs implements keycdn.API
+func (f *Fake) TopURLs(ctx context.Context, zoneID uint64, from, to time.Time, limit int) ([]keycdn.URLStat, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("TopURLs", zoneID, from, to, limit); err != nil {
+		return nil, err
+	}
+	stats := append([]keycdn.URLStat(nil), f.topURLs[zoneID]...)
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Requests > stats[j].Requests
+	})
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+// Usage implements keycdn.API. It returns keycdn.ErrNoData unless usage was
+// seeded.
+func (f *Fake) Usage(ctx context.Context, from, to time.Time) (keycdn.Usage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Usage", from, to); err != nil {
+		return keycdn.Usage{}, err
+	}
+	if f.usage == nil {
+		return keycdn.Usage{}, keycdn.ErrNoData
+	}
+	return *f.usage, nil
+}
+
+// AccountLimits implements keycdn.API. The quota is unlimited unless
+// seeded with SetLimits.
+func (f *Fake) AccountLimits(ctx context.Context) (keycdn.AccountLimits, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("AccountLimits"); err != nil {
+		return keycdn.AccountLimits{}, err
+	}
+	l := f.limits
+	l.ZonesUsed = len(f.zones)
+	l.ZoneAliasesUsed = len(f.aliases)
+	return l, nil
+}
+
+// Do implements keycdn.API. Raw requests are recorded but not supported, they
+// always fail.
+func (f *Fake) Do(ctx context.Context, method, path string, query url.Values, body interface{}) ([]byte, *keycdn.ResponseMeta, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Do", method, path, query, body); err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, fmt.Errorf("keycdntest: %s %s is not supported by Fake", method, path)
+}
@@ -0,0 +1,87 @@
+package keycdntest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+)
+
+func TestFakeEditZoneKeepsUnsetSettings(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake()
+	z := f.SeedZone(keycdn.Zone{Name: "assets", OriginURL: "https://example.com", CORS: true, Expire: 60})
+
+	got, err := f.EditZone(ctx, keycdn.Zone{ID: z.ID, Gzip: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "assets" || got.OriginURL != "https://example.com" || !got.CORS || got.Expire != 60 || !got.Gzip {
+		t.Errorf("EditZone = %+v, want only gzip changed", got)
+	}
+}
+
+func TestFakeApplyZone(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake()
+	z := f.SeedZone(keycdn.Zone{Name: "assets", OriginURL: "https://example.com", CORS: true})
+
+	got, err := f.ApplyZone(ctx, keycdn.Zone{Name: "assets", Expire: 120})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != z.ID || got.OriginURL != "https://example.com" || !got.CORS || got.Expire != 120 {
+		t.Errorf("ApplyZone = %+v, want only expire changed", got)
+	}
+
+	created, err := f.ApplyZone(ctx, keycdn.Zone{Name: "images", Gzip: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == z.ID || !created.Gzip {
+		t.Errorf("ApplyZone = %+v, want a new zone", created)
+	}
+
+	if _, err := f.ApplyZone(ctx, keycdn.Zone{Name: "assets", OriginURL: "ftp://example.com"}); err == nil {
+		t.Error("ApplyZone accepted an invalid origin")
+	}
+}
+
+func TestServerEditZoneDisables(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets", CORS: true, HTTP2: true})
+
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.UpdateZone(ctx, keycdn.NewZoneUpdate(z.ID).SetCORS(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CORS || !got.HTTP2 || got.Name != "assets" {
+		t.Errorf("UpdateZone = %+v, want cors disabled and http2 enabled", got)
+	}
+}
+
+func TestFakeStatsSummary(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake()
+	z := f.SeedZone(keycdn.Zone{Name: "assets"})
+	stats := map[string]uint64{"totalcachehit": 3, "totalcachemiss": 1, "totalsuccess": 9, "totalerror": 1}
+	f.SetStats(z.ID, stats)
+
+	got, err := f.StatsSummary(ctx, z.ID, time.Time{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := keycdn.SummarizeStats(stats); got != want {
+		t.Errorf("StatsSummary = %+v, want %+v", got, want)
+	}
+	if got.HitRatio != 0.75 || got.ErrorRate != 0.1 {
+		t.Errorf("StatsSummary = %+v", got)
+	}
+}
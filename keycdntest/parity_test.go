@@ -0,0 +1,125 @@
+package keycdntest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dominikschulz/keycdn/v2"
+)
+
+// parity runs call against a Fake and against a Client talking to a Server
+// backed by an identically seeded Fake and requires both to fail the same.
+// API errors are compared by their fields except for Op, which the Client
+// derives from the HTTP request.
+func parity(t *testing.T, seed func(f *Fake), call func(api keycdn.API) error) error {
+	t.Helper()
+	f := NewFake()
+	seed(f)
+	s := NewServer()
+	defer s.Close()
+	seed(s.Fake)
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeErr, clientErr := call(f), call(c)
+	if fakeErr == nil || clientErr == nil {
+		t.Fatalf("fake err = %v, client err = %v, want both to fail", fakeErr, clientErr)
+	}
+	var fakeAPIErr, clientAPIErr *keycdn.APIError
+	if errors.As(fakeErr, &fakeAPIErr) != errors.As(clientErr, &clientAPIErr) {
+		t.Fatalf("fake err = %#v, client err = %#v, want both or neither to be an *APIError", fakeErr, clientErr)
+	}
+	if fakeAPIErr != nil {
+		fe, ce := *fakeAPIErr, *clientAPIErr
+		fe.Op, ce.Op = "", ""
+		if fe != ce {
+			t.Errorf("fake err = %+v, client err = %+v", fe, ce)
+		}
+	} else if fakeErr.Error() != clientErr.Error() {
+		t.Errorf("fake err = %q, client err = %q", fakeErr, clientErr)
+	}
+	for _, target := range []error{keycdn.ErrNotFound, keycdn.ErrZoneNotFound} {
+		if errors.Is(fakeErr, target) != errors.Is(clientErr, target) {
+			t.Errorf("errors.Is(%v): fake %t, client %t", target, errors.Is(fakeErr, target), errors.Is(clientErr, target))
+		}
+	}
+	return fakeErr
+}
+
+func TestParityDuplicateNames(t *testing.T) {
+	ctx := context.Background()
+	seed := func(f *Fake) {
+		f.SeedZone(keycdn.Zone{Name: "assets"})
+		f.SeedZone(keycdn.Zone{Name: "assets"})
+	}
+	for name, call := range map[string]func(keycdn.API) error{
+		"ZoneByName": func(api keycdn.API) error {
+			_, err := api.ZoneByName(ctx, "assets")
+			return err
+		},
+		"ZoneNameIndex": func(api keycdn.API) error {
+			idx, err := api.ZoneNameIndex(ctx)
+			if idx != nil {
+				t.Errorf("ZoneNameIndex = %v, want nil", idx)
+			}
+			return err
+		},
+		"CreateZoneIfNotExists": func(api keycdn.API) error {
+			_, _, err := api.CreateZoneIfNotExists(ctx, keycdn.Zone{Name: "assets"})
+			return err
+		},
+		"ApplyZone": func(api keycdn.API) error {
+			_, err := api.ApplyZone(ctx, keycdn.Zone{Name: "assets", Expire: 60})
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := parity(t, seed, call)
+			if want := `multiple zones named "assets"`; err.Error() != want {
+				t.Errorf("err = %q, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestParityNotFound(t *testing.T) {
+	ctx := context.Background()
+	seed := func(f *Fake) {
+		f.SeedZone(keycdn.Zone{Name: "assets"})
+	}
+	for name, tc := range map[string]struct {
+		call         func(keycdn.API) error
+		zoneNotFound bool
+	}{
+		"ZoneByName": {func(api keycdn.API) error {
+			_, err := api.ZoneByName(ctx, "images")
+			return err
+		}, true},
+		"Zone": {func(api keycdn.API) error {
+			_, err := api.Zone(ctx, 42)
+			return err
+		}, true},
+		"DeleteEdgeRule": {func(api keycdn.API) error {
+			return api.DeleteEdgeRule(ctx, 42)
+		}, false},
+		"DeleteZoneAlias": {func(api keycdn.API) error {
+			return api.DeleteZoneAlias(ctx, 42)
+		}, false},
+		"DeleteZoneReferrer": {func(api keycdn.API) error {
+			return api.DeleteZoneReferrer(ctx, 42)
+		}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := parity(t, seed, tc.call)
+			if !errors.Is(err, keycdn.ErrNotFound) {
+				t.Errorf("err = %v, want it to match ErrNotFound", err)
+			}
+			if errors.Is(err, keycdn.ErrZoneNotFound) != tc.zoneNotFound {
+				t.Errorf("err = %v, matches ErrZoneNotFound: %t, want %t", err, !tc.zoneNotFound, tc.zoneNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package keycdntest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dominikschulz/keycdn/v2"
+)
+
+// gzipTransport compresses the responses of http.DefaultTransport like the
+// real API does
+type gzipTransport struct{}
+
+func (gzipTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, resp.Body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	resp.Header.Set("Content-Encoding", "gzip")
+	resp.Body = io.NopCloser(&buf)
+	resp.ContentLength = int64(buf.Len())
+	return resp, nil
+}
+
+func TestRecorder(t *testing.T) {
+	const key = "sk_0123456789abcdef"
+	ctx := context.Background()
+	fixture := filepath.Join(t.TempDir(), "zones.json")
+	s := NewServer()
+	s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	baseURL := s.URL
+
+	rec, err := NewRecorder(fixture, ModeRecord, gzipTransport{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := keycdn.New(key, keycdn.WithBaseURL(baseURL), keycdn.WithHTTPClient(&http.Client{Transport: rec}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := c.Zone(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := c.AddZone(ctx, keycdn.ZoneCreateRequest{Name: "images"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	b, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{key, "Authorization", "Content-Encoding"} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("fixture contains %q:\n%s", secret, b)
+		}
+	}
+	if !strings.Contains(string(b), `"name=images`) {
+		t.Errorf("fixture lacks the request body of AddZone:\n%s", b)
+	}
+
+	// the server is gone, all answers come from the fixture
+	rec, err = NewRecorder(fixture, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = keycdn.New("unused", keycdn.WithBaseURL(baseURL), keycdn.WithHTTPClient(&http.Client{Transport: rec}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z, err := c.Zone(ctx, 1); err != nil || z != recorded {
+		t.Errorf("replayed Zone = %+v, %v, want %+v", z, err, recorded)
+	}
+	if z, err := c.AddZone(ctx, keycdn.ZoneCreateRequest{Name: "images"}); err != nil || z != created {
+		t.Errorf("replayed AddZone = %+v, %v, want %+v", z, err, created)
+	}
+	// every interaction is replayed once and requests must match exactly
+	if _, err := c.Zone(ctx, 1); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Errorf("second replay of Zone: err = %v, want no recorded interaction", err)
+	}
+	if _, err := c.AddZone(ctx, keycdn.ZoneCreateRequest{Name: "videos"}); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Errorf("AddZone with another body: err = %v, want no recorded interaction", err)
+	}
+}
+
+func TestRecorderMissingFixture(t *testing.T) {
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil); err == nil {
+		t.Error("NewRecorder succeeded without a fixture in replay mode")
+	}
+	// record mode creates the fixture on Save
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeRecord, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,611 @@
+package keycdntest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/internal/zonewire"
+)
+
+// Server is a fake KeyCDN API for integration tests. It implements the
+// zone, edge rule, zone alias, zone referrer, purge, report and account
+// limit endpoints on top of a Fake which holds the data:
+//
+//	s := keycdntest.NewServer()
+//	defer s.Close()
+//	s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+//	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL))
+//
+// Requests without an API key, sent with basic auth or as bearer token, are
+// rejected with 401 Unauthorized.
+type Server struct {
+	*httptest.Server
+	// Fake holds the zones and report data served and records all
+	// operations
+	Fake *Fake
+
+	mu       sync.Mutex
+	failures map[string][]failure
+	requests map[string]int
+}
+
+// failure is an injected error response
+type failure struct {
+	status     int
+	retryAfter string
+}
+
+// NewServer starts a new server backed by an empty Fake
+func NewServer() *Server {
+	s := &Server{
+		Fake:     NewFake(),
+		failures: make(map[string][]failure),
+		requests: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Fail makes the next times requests to path, e.g. "/zones.json", fail with
+// the given HTTP status. Failures of the same path are queued.
+func (s *Server) Fail(path string, status, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < times; i++ {
+		s.failures[path] = append(s.failures[path], failure{status: status})
+	}
+}
+
+// Throttle makes the next times requests to path fail with 429 Too Many
+// Requests and the given Retry-After header, which is omitted if empty.
+// Failures of the same path are queued.
+func (s *Server) Throttle(path, retryAfter string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < times; i++ {
+		s.failures[path] = append(s.failures[path], failure{status: http.StatusTooManyRequests, retryAfter: retryAfter})
+	}
+}
+
+// Requests returns the number of requests received for path, including
+// rejected ones
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// count records a request for path
+func (s *Server) count(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[path]++
+}
+
+// failure returns the next injected failure for path, if any
+func (s *Server) failure(path string) (failure, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.failures[path]
+	if len(q) == 0 {
+		return failure{}, false
+	}
+	s.failures[path] = q[1:]
+	return q[0], true
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.count(r.URL.Path)
+	if apiKey(r) == "" {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if f, failed := s.failure(r.URL.Path); failed {
+		if f.retryAfter != "" {
+			w.Header().Set("Retry-After", f.retryAfter)
+		}
+		writeError(w, f.status, http.StatusText(f.status))
+		return
+	}
+
+	path := r.URL.Path
+	switch {
+	case path == "/zones.json" && r.Method == http.MethodGet:
+		s.listZones(w, r)
+	case path == "/zones.json" && r.Method == http.MethodPost:
+		s.createZone(w, r)
+	case strings.HasPrefix(path, "/zones/purge/") && r.Method == http.MethodGet:
+		s.purge(w, r, "cache")
+	case strings.HasPrefix(path, "/zones/purgeurl/") && r.Method == http.MethodDelete:
+		s.purge(w, r, "url")
+	case strings.HasPrefix(path, "/zones/purgetag/") && r.Method == http.MethodDelete:
+		s.purge(w, r, "tag")
+	case strings.HasPrefix(path, "/zones/") && r.Method == http.MethodGet:
+		s.getZone(w, r)
+	case strings.HasPrefix(path, "/zones/") && r.Method == http.MethodPut:
+		s.editZone(w, r)
+	case strings.HasPrefix(path, "/zones/") && r.Method == http.MethodDelete:
+		s.deleteZone(w, r)
+	case path == "/edgerules.json" && r.Method == http.MethodGet:
+		s.listEdgeRules(w, r)
+	case path == "/edgerules.json" && r.Method == http.MethodPost:
+		s.createEdgeRule(w, r)
+	case strings.HasPrefix(path, "/edgerules/") && r.Method == http.MethodDelete:
+		s.deleteEdgeRule(w, r)
+	case path == "/zonealiases.json" && r.Method == http.MethodGet:
+		s.listZoneAliases(w, r)
+	case path == "/zonealiases.json" && r.Method == http.MethodPost:
+		s.addZoneAlias(w, r)
+	case strings.HasPrefix(path, "/zonealiases/") && r.Method == http.MethodDelete:
+		s.deleteZoneAlias(w, r)
+	case path == "/zonereferrers.json" && r.Method == http.MethodGet:
+		s.listZoneReferrers(w, r)
+	case path == "/zonereferrers.json" && r.Method == http.MethodPost:
+		s.addZoneReferrer(w, r)
+	case strings.HasPrefix(path, "/zonereferrers/") && r.Method == http.MethodDelete:
+		s.deleteZoneReferrer(w, r)
+	case path == "/account/limits.json" && r.Method == http.MethodGet:
+		s.limits(w, r)
+	case strings.HasPrefix(path, "/reports/"):
+		s.report(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) listZones(w http.ResponseWriter, r *http.Request) {
+	it := s.Fake.ZoneIterator(r.Context())
+	var zones []map[string]string
+	for it.Next() {
+		zones = append(zones, zoneWire(it.Value()))
+	}
+	if err := it.Err(); err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, limit := atoi(r.FormValue("page"), 1), atoi(r.FormValue("limit"), len(zones))
+	start := (page - 1) * limit
+	if start > len(zones) || start < 0 {
+		start = len(zones)
+	}
+	end := start + limit
+	if end > len(zones) {
+		end = len(zones)
+	}
+	writeData(w, "zones", zones[start:end])
+}
+
+func (s *Server) getZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "/zones/")
+	if !ok {
+		return
+	}
+	z, err := s.Fake.Zone(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeData(w, "zone", zoneWire(z))
+}
+
+func (s *Server) createZone(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if r.PostForm.Get("name") == "" {
+		writeError(w, http.StatusBadRequest, "Zone name is required")
+		return
+	}
+	z, err := s.Fake.AddZone(r.Context(), zoneFromWire(r.PostForm).CreateRequest())
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeData(w, "zone", zoneWire(z))
+}
+
+func (s *Server) editZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "/zones/")
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// only the sent parameters are changed, so disabled settings are
+	// applied as well
+	var d keycdn.ZoneDiff
+	for _, f := range zonewire.Fields {
+		if _, set := r.PostForm[f.Param]; set {
+			d = append(d, keycdn.FieldChange{Param: f.Param, New: r.PostForm.Get(f.Param)})
+		}
+	}
+	z, err := s.Fake.UpdateZone(r.Context(), d.Update(id))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeData(w, "zone", zoneWire(z))
+}
+
+func (s *Server) deleteZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "/zones/")
+	if !ok {
+		return
+	}
+	if err := s.Fake.DeleteZone(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success", "description": "Zone deleted"})
+}
+
+func (s *Server) listEdgeRules(w http.ResponseWriter, r *http.Request) {
+	zoneID, _ := strconv.ParseUint(r.FormValue("zone_id"), 10, 64)
+	rules, err := s.Fake.ZoneEdgeRules(r.Context(), zoneID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	entries := make([]map[string]string, 0, len(rules))
+	for _, rule := range rules {
+		entries = append(entries, edgeRuleWire(rule))
+	}
+	writeData(w, "edgerules", entries)
+}
+
+func (s *Server) createEdgeRule(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	zoneID, _ := strconv.ParseUint(r.PostForm.Get("zone_id"), 10, 64)
+	rule, err := s.Fake.CreateEdgeRule(r.Context(), zoneID, keycdn.EdgeRule{
+		Name:    r.PostForm.Get("name"),
+		Type:    r.PostForm.Get("type"),
+		Matcher: r.PostForm.Get("matcher"),
+		Action:  r.PostForm.Get("action"),
+	})
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeData(w, "edgerule", edgeRuleWire(rule))
+}
+
+func (s *Server) deleteEdgeRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "/edgerules/")
+	if !ok {
+		return
+	}
+	if err := s.Fake.DeleteEdgeRule(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success", "description": "Edge rule deleted"})
+}
+
+func edgeRuleWire(rule keycdn.EdgeRule) map[string]string {
+	return map[string]string{
+		"id":      strconv.FormatUint(rule.ID, 10),
+		"zone_id": strconv.FormatUint(rule.ZoneID, 10),
+		"name":    rule.Name,
+		"type":    rule.Type,
+		"matcher": rule.Matcher,
+		"action":  rule.Action,
+	}
+}
+
+func (s *Server) listZoneAliases(w http.ResponseWriter, r *http.Request) {
+	aliases, err := s.Fake.ZoneAliases(r.Context())
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	entries := make([]map[string]string, 0, len(aliases))
+	for _, a := range aliases {
+		entries = append(entries, zoneAliasWire(a))
+	}
+	writeData(w, "zonealiases", entries)
+}
+
+func (s *Server) addZoneAlias(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	zoneID, _ := strconv.ParseUint(r.PostForm.Get("zone_id"), 10, 64)
+	a, err := s.Fake.AddZoneAlias(r.Context(), zoneID, r.PostForm.Get("name"))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeData(w, "zonealias", zoneAliasWire(a))
+}
+
+func (s *Server) deleteZoneAlias(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "/zonealiases/")
+	if !ok {
+		return
+	}
+	if err := s.Fake.DeleteZoneAlias(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success", "description": "Zone alias deleted"})
+}
+
+func zoneAliasWire(a keycdn.ZoneAlias) map[string]string {
+	return map[string]string{
+		"id":      strconv.FormatUint(a.ID, 10),
+		"zone_id": strconv.FormatUint(a.ZoneID, 10),
+		"name":    a.Name,
+	}
+}
+
+func (s *Server) listZoneReferrers(w http.ResponseWriter, r *http.Request) {
+	refs, err := s.Fake.ZoneReferrers(r.Context())
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	entries := make([]map[string]string, 0, len(refs))
+	for _, ref := range refs {
+		entries = append(entries, zoneReferrerWire(ref))
+	}
+	writeData(w, "zonereferrers", entries)
+}
+
+func (s *Server) addZoneReferrer(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	zoneID, _ := strconv.ParseUint(r.PostForm.Get("zone_id"), 10, 64)
+	ref, err := s.Fake.AddZoneReferrer(r.Context(), zoneID, r.PostForm.Get("name"))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeData(w, "zonereferrer", zoneReferrerWire(ref))
+}
+
+func (s *Server) deleteZoneReferrer(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "/zonereferrers/")
+	if !ok {
+		return
+	}
+	if err := s.Fake.DeleteZoneReferrer(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success", "description": "Zone referrer deleted"})
+}
+
+func zoneReferrerWire(ref keycdn.ZoneReferrer) map[string]string {
+	return map[string]string{
+		"id":      strconv.FormatUint(ref.ID, 10),
+		"zone_id": strconv.FormatUint(ref.ZoneID, 10),
+		"name":    ref.Name,
+	}
+}
+
+func (s *Server) limits(w http.ResponseWriter, r *http.Request) {
+	l, err := s.Fake.AccountLimits(r.Context())
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeData(w, "limits", map[string]string{
+		"zones":       strconv.Itoa(l.Zones),
+		"zonealiases": strconv.Itoa(l.ZoneAliases),
+	})
+}
+
+func (s *Server) purge(w http.ResponseWriter, r *http.Request, kind string) {
+	prefix := r.URL.Path[:strings.LastIndex(r.URL.Path, "/")+1]
+	id, ok := pathID(w, r, prefix)
+	if !ok {
+		return
+	}
+	var body struct {
+		URLs     []string `json:"urls"`
+		Tags     []string `json:"tags"`
+		Wildcard bool     `json:"wildcard"`
+	}
+	if kind != "cache" {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	var err error
+	switch {
+	case kind == "cache":
+		err = s.Fake.PurgeZoneCache(r.Context(), id)
+	case kind == "tag":
+		err = s.Fake.PurgeZoneTag(r.Context(), id, body.Tags)
+	case body.Wildcard:
+		err = s.Fake.PurgeZonePrefix(r.Context(), id, body.URLs)
+	default:
+		err = s.Fake.PurgeZoneURL(r.Context(), id, body.URLs)
+	}
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "success", "description": "Cache has been cleared"})
+}
+
+func (s *Server) report(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	from := time.Unix(int64(atoi(r.FormValue("start"), 0)), 0)
+	to := time.Unix(int64(atoi(r.FormValue("end"), 0)), 0)
+	zoneID, _ := strconv.ParseUint(r.FormValue("zone_id"), 10, 64)
+
+	var stats interface{}
+	var err error
+	switch r.URL.Path {
+	case "/reports/traffic.json":
+		var t uint64
+		t, err = s.Fake.Traffic(ctx, zoneID, from, to)
+		stats = []map[string]string{{"amount": strconv.FormatUint(t, 10), "timestamp": strconv.FormatInt(from.Unix(), 10)}}
+	case "/reports/image-processing.json":
+		var n uint64
+		n, err = s.Fake.ProcessedImages(ctx, zoneID, from, to)
+		stats = []map[string]string{{"amount": strconv.FormatUint(n, 10), "timestamp": strconv.FormatInt(from.Unix(), 10)}}
+	case "/reports/statestats.json":
+		var m map[string]uint64
+		m, err = s.Fake.Stats(ctx, zoneID, from, to)
+		stats = []map[string]string{uintStrings(m)}
+	case "/reports/topurls.json":
+		var us []keycdn.URLStat
+		us, err = s.Fake.TopURLs(ctx, zoneID, from, to, atoi(r.FormValue("limit"), 0))
+		entries := make([]map[string]string, 0, len(us))
+		for _, u := range us {
+			entries = append(entries, map[string]string{"url": u.URL, "amount": strconv.FormatUint(u.Requests, 10)})
+		}
+		stats = entries
+	case "/reports/usage.json":
+		var u keycdn.Usage
+		u, err = s.Fake.Usage(ctx, from, to)
+		stats = []map[string]string{uintStrings(map[string]uint64{"traffic": u.Traffic, "requests": u.Requests})}
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if errors.Is(err, keycdn.ErrNoData) {
+		stats, err = []struct{}{}, nil
+	}
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeData(w, "stats", stats)
+}
+
+// apiKey returns the key sent with basic auth or as bearer token
+func apiKey(r *http.Request) string {
+	if key, _, ok := r.BasicAuth(); ok {
+		return key
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+}
+
+// pathID parses the ID in paths like /zones/1.json
+func pathID(w http.ResponseWriter, r *http.Request, prefix string) (uint64, bool) {
+	s := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, prefix), ".json")
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not found")
+		return 0, false
+	}
+	return id, true
+}
+
+func atoi(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func uintStrings(m map[string]uint64) map[string]string {
+	ret := make(map[string]string, len(m))
+	for k, v := range m {
+		ret[k] = strconv.FormatUint(v, 10)
+	}
+	return ret
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeData(w http.ResponseWriter, key string, v interface{}) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":      "success",
+		"description": "",
+		"data":        map[string]interface{}{key: v},
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, description string) {
+	writeJSON(w, status, map[string]string{"status": "error", "description": description})
+}
+
+// writeErr answers with the HTTP status of an APIError or 500
+func writeErr(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	description := err.Error()
+	if e, ok := err.(*keycdn.APIError); ok {
+		if e.StatusCode != 0 {
+			status = e.StatusCode
+		}
+		description = e.Description
+	}
+	writeError(w, status, description)
+}
+
+// zoneWire returns the zone in the representation of the API, where all
+// values are strings and booleans are "enabled" or "disabled"
+func zoneWire(z keycdn.Zone) map[string]string {
+	rv := reflect.ValueOf(z)
+	m := make(map[string]string, len(zonewire.Fields)+1)
+	m["id"] = strconv.FormatUint(z.ID, 10)
+	for _, f := range zonewire.Fields {
+		switch v := rv.FieldByName(f.Name); f.Kind {
+		case zonewire.Flag:
+			m[f.Param] = zonewire.Disabled
+			if v.Bool() {
+				m[f.Param] = zonewire.Enabled
+			}
+		case zonewire.Number:
+			m[f.Param] = strconv.FormatInt(v.Int(), 10)
+		default:
+			m[f.Param] = v.String()
+		}
+	}
+	return m
+}
+
+// zoneFromWire parses a zone from the form parameters of the API
+func zoneFromWire(vs url.Values) keycdn.Zone {
+	var z keycdn.Zone
+	rv := reflect.ValueOf(&z).Elem()
+	for _, f := range zonewire.Fields {
+		if _, set := vs[f.Param]; !set {
+			continue
+		}
+		v := vs.Get(f.Param)
+		switch field := rv.FieldByName(f.Name); f.Kind {
+		case zonewire.Flag:
+			field.SetBool(v == zonewire.Enabled)
+		case zonewire.Number:
+			n, _ := strconv.ParseInt(v, 10, 64)
+			field.SetInt(n)
+		default:
+			field.SetString(v)
+		}
+	}
+	z.CunstomSSLCert = z.CustomSSLCert
+	return z
+}
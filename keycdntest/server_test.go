@@ -0,0 +1,43 @@
+package keycdntest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/dominikschulz/keycdn/v2"
+)
+
+func TestServerAuth(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+
+	for name, mode := range map[string]keycdn.AuthMode{"basic": keycdn.AuthBasic, "bearer": keycdn.AuthBearer} {
+		c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL), keycdn.WithAuthMode(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Zone(context.Background(), 1); err != nil {
+			t.Errorf("%s auth: %v", name, err)
+		}
+	}
+
+	for name, auth := range map[string]string{"missing": "", "empty bearer token": "Bearer ", "other scheme": "Token key"} {
+		req, err := http.NewRequest(http.MethodGet, s.URL+"/zones/1.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s key: HTTP %d, want 401", name, resp.StatusCode)
+		}
+	}
+}
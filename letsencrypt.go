@@ -0,0 +1,111 @@
+package keycdn
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// zoneDomain is the domain of the default zone URLs. Aliases need a CNAME
+// record pointing to a host in it.
+const zoneDomain = ".kxcdn.com"
+
+// servedCertificate returns the certificate presented by the edge servers
+// for host
+func servedCertificate(ctx context.Context, host string) (*x509.Certificate, error) {
+	d := tls.Dialer{Config: &tls.Config{ServerName: host}}
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, "443"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.(*tls.Conn).ConnectionState().PeerCertificates[0], nil
+}
+
+// EnableLetsEncrypt switches a zone to a Let's Encrypt certificate and waits
+// until it is served. Let's Encrypt issues the certificate for the aliases
+// of the zone, so the zone needs at least one alias whose CNAME record
+// points to the zone. This is checked before the zone is changed.
+//
+// Issuance is complete once the edge servers present a certificate issued by
+// Let's Encrypt for the first alias. If KeyCDN gives up and reverts the zone
+// to another certificate, or ctx expires first, the error describes the last
+// observed state.
+func (c *Client) EnableLetsEncrypt(ctx context.Context, zoneID uint64) (Zone, error) {
+	aliases, err := c.ZoneAliases(ctx)
+	if err != nil {
+		return Zone{}, err
+	}
+	var hosts []string
+	for _, a := range aliases {
+		if a.ZoneID == zoneID {
+			hosts = append(hosts, a.Name)
+		}
+	}
+	if len(hosts) == 0 {
+		return Zone{}, fmt.Errorf("Failed to enable Let's Encrypt for Zone %d: the zone has no aliases", zoneID)
+	}
+	for _, host := range hosts {
+		cname, err := c.lookupCNAME(ctx, host)
+		if err != nil {
+			return Zone{}, fmt.Errorf("Failed to enable Let's Encrypt for Zone %d: failed to look up alias %s: %w", zoneID, host, err)
+		}
+		if !strings.HasSuffix(strings.TrimSuffix(cname, "."), zoneDomain) {
+			return Zone{}, fmt.Errorf("Failed to enable Let's Encrypt for Zone %d: alias %s points to %s instead of the zone", zoneID, host, cname)
+		}
+	}
+
+	vs := url.Values{}
+	vs.Set("sslcert", SSLCertLetsEncrypt)
+	z, err := c.editZone(ctx, zoneID, vs)
+	if err != nil || c.dryRun {
+		return z, err
+	}
+
+	pollInterval := time.Second
+	for {
+		cert, err := c.servedCertificate(ctx, hosts[0])
+		if err == nil && isLetsEncrypt(cert) {
+			return z, nil
+		}
+		state := "the certificate could not be fetched"
+		if err == nil {
+			state = fmt.Sprintf("%s is still served with a certificate issued by %q", hosts[0], cert.Issuer)
+		} else if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			state = fmt.Sprintf("%s: %s", state, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return z, fmt.Errorf("Let's Encrypt certificate for Zone %d not issued yet, %s: %w", zoneID, state, ctx.Err())
+		case <-c.clock.After(pollInterval):
+		}
+		if next := pollInterval * 2; next <= maxPollInterval {
+			pollInterval = next
+		}
+
+		z, err = c.Zone(ctx, zoneID)
+		if err != nil {
+			return z, err
+		}
+		if z.SSLCert != SSLCertLetsEncrypt {
+			return z, fmt.Errorf("Failed to enable Let's Encrypt for Zone %d: the zone was reverted to %s certificates, %s", zoneID, z.SSLCert, state)
+		}
+	}
+}
+
+// isLetsEncrypt returns true if the certificate was issued by Let's Encrypt
+func isLetsEncrypt(cert *x509.Certificate) bool {
+	for _, o := range cert.Issuer.Organization {
+		if o == "Let's Encrypt" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package keycdn_test
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+// issuedBy returns a certificate issued by the given organization
+func issuedBy(org string) *x509.Certificate {
+	return &x509.Certificate{Issuer: pkix.Name{Organization: []string{org}}}
+}
+
+// letsEncryptSetup returns a client of a server with zone assets and its
+// alias cdn.example.com
+func letsEncryptSetup(t *testing.T) (*keycdntest.Server, *keycdn.Client, *keycdntest.Clock, keycdn.Zone) {
+	t.Helper()
+	s := keycdntest.NewServer()
+	t.Cleanup(s.Close)
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets", SSLCert: keycdn.SSLCertShared})
+	if _, err := s.Fake.AddZoneAlias(context.Background(), z.ID, "cdn.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	clock := keycdntest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.AutoAdvance(true)
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL), keycdn.WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, c, clock, z
+}
+
+func cnameOfZone(ctx context.Context, host string) (string, error) {
+	return "assets-1.kxcdn.com.", nil
+}
+
+func TestEnableLetsEncrypt(t *testing.T) {
+	_, c, clock, z := letsEncryptSetup(t)
+	var fetched []string
+	keycdn.SetEdgeProbes(c, cnameOfZone, func(ctx context.Context, host string) (*x509.Certificate, error) {
+		fetched = append(fetched, host)
+		if len(fetched) < 3 {
+			return issuedBy("KeyCDN"), nil
+		}
+		return issuedBy("Let's Encrypt"), nil
+	})
+
+	got, err := c.EnableLetsEncrypt(context.Background(), z.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SSLCert != keycdn.SSLCertLetsEncrypt {
+		t.Errorf("SSLCert = %q, want %q", got.SSLCert, keycdn.SSLCertLetsEncrypt)
+	}
+	if want := []string{"cdn.example.com", "cdn.example.com", "cdn.example.com"}; !reflect.DeepEqual(fetched, want) {
+		t.Errorf("fetched certificates of %v, want %v", fetched, want)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(clock.Waits(), want) {
+		t.Errorf("waits = %v, want %v", clock.Waits(), want)
+	}
+}
+
+func TestEnableLetsEncryptChecksDNS(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name   string
+		lookup func(ctx context.Context, host string) (string, error)
+		want   string
+	}{
+		{"foreign CNAME", func(ctx context.Context, host string) (string, error) {
+			return "www.example.net.", nil
+		}, "alias cdn.example.com points to www.example.net."},
+		{"lookup failure", func(ctx context.Context, host string) (string, error) {
+			return "", errors.New("no such host")
+		}, "failed to look up alias cdn.example.com: no such host"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, c, _, z := letsEncryptSetup(t)
+			keycdn.SetEdgeProbes(c, tc.lookup, func(ctx context.Context, host string) (*x509.Certificate, error) {
+				t.Error("certificate fetched before the zone was changed")
+				return nil, errors.New("unexpected")
+			})
+			if _, err := c.EnableLetsEncrypt(ctx, z.ID); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want it to contain %q", err, tc.want)
+			}
+			if got, _ := s.Fake.Zone(ctx, z.ID); got.SSLCert != keycdn.SSLCertShared {
+				t.Errorf("SSLCert = %q, want the zone unchanged", got.SSLCert)
+			}
+		})
+	}
+}
+
+func TestEnableLetsEncryptWithoutAlias(t *testing.T) {
+	ctx := context.Background()
+	s, c, _, _ := letsEncryptSetup(t)
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "images", SSLCert: keycdn.SSLCertShared})
+	keycdn.SetEdgeProbes(c, cnameOfZone, nil)
+
+	if _, err := c.EnableLetsEncrypt(ctx, z.ID); err == nil || !strings.Contains(err.Error(), "has no aliases") {
+		t.Errorf("err = %v, want an error about the missing alias", err)
+	}
+	if got, _ := s.Fake.Zone(ctx, z.ID); got.SSLCert != keycdn.SSLCertShared {
+		t.Errorf("SSLCert = %q, want the zone unchanged", got.SSLCert)
+	}
+}
+
+func TestEnableLetsEncryptReverted(t *testing.T) {
+	ctx := context.Background()
+	s, c, _, z := letsEncryptSetup(t)
+	keycdn.SetEdgeProbes(c, cnameOfZone, func(ctx context.Context, host string) (*x509.Certificate, error) {
+		// KeyCDN gives up on issuing and reverts the zone
+		if _, err := s.Fake.UpdateZone(ctx, keycdn.NewZoneUpdate(z.ID).SetSSLCert(keycdn.SSLCertShared)); err != nil {
+			t.Error(err)
+		}
+		return issuedBy("KeyCDN"), nil
+	})
+
+	_, err := c.EnableLetsEncrypt(ctx, z.ID)
+	if err == nil || !strings.Contains(err.Error(), "reverted to shared certificates") || !strings.Contains(err.Error(), `issued by "O=KeyCDN"`) {
+		t.Errorf("err = %v, want it to describe the revert and the served certificate", err)
+	}
+}
+
+func TestEnableLetsEncryptContext(t *testing.T) {
+	_, c, clock, z := letsEncryptSetup(t)
+	// the clock does not advance, so only the context ends the wait
+	clock.AutoAdvance(false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keycdn.SetEdgeProbes(c, cnameOfZone, func(ctx context.Context, host string) (*x509.Certificate, error) {
+		cancel()
+		return nil, errors.New("connection refused")
+	})
+
+	_, err := c.EnableLetsEncrypt(ctx, z.ID)
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "could not be fetched: connection refused") {
+		t.Errorf("err = %v, want context.Canceled with the last fetch error", err)
+	}
+}
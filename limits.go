@@ -0,0 +1,72 @@
+package keycdn
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// AccountLimits is the zone quota of the account and its usage. A limit of
+// 0 means unlimited.
+type AccountLimits struct {
+	Zones           int
+	ZonesUsed       int
+	ZoneAliases     int
+	ZoneAliasesUsed int
+}
+
+type limitsResp map[string]string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *limitsResp) UnmarshalJSON(b []byte) error {
+	m, err := unmarshalFlexMap(b)
+	*r = m
+	return err
+}
+
+// AccountLimits returns the zone and zone alias quota of the account
+// together with the number of zones and aliases in use
+func (c *Client) AccountLimits(ctx context.Context) (AccountLimits, error) {
+	b, err := c.get(ctx, "/account/limits.json", nil)
+	if err != nil {
+		return AccountLimits{}, err
+	}
+	r, err := decodeData[limitsResp](c, "/account/limits.json", b, "limits", "Failed to get account limits")
+	if err != nil {
+		return AccountLimits{}, err
+	}
+	l := AccountLimits{}
+	l.Zones, _ = strconv.Atoi(r["zones"])
+	l.ZoneAliases, _ = strconv.Atoi(r["zonealiases"])
+
+	zones, err := c.Zones(ctx)
+	if err != nil {
+		return l, err
+	}
+	aliases, err := c.ZoneAliases(ctx)
+	if err != nil {
+		return l, err
+	}
+	l.ZonesUsed = len(zones)
+	l.ZoneAliasesUsed = len(aliases)
+	return l, nil
+}
+
+// CheckZones returns an error matching ErrQuotaExceeded if n more zones
+// would exceed the quota
+func (l AccountLimits) CheckZones(n int) error {
+	return checkQuota("zone", l.Zones, l.ZonesUsed, n)
+}
+
+// CheckZoneAliases returns an error matching ErrQuotaExceeded if n more
+// zone aliases would exceed the quota
+func (l AccountLimits) CheckZoneAliases(n int) error {
+	return checkQuota("zone alias", l.ZoneAliases, l.ZoneAliasesUsed, n)
+}
+
+func checkQuota(kind string, limit, used, n int) error {
+	if limit > 0 && used+n > limit {
+		return fmt.Errorf("%w: %d of %d %s slots used, %d more requested", ErrQuotaExceeded, used, limit, kind, n)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package keycdn
+
+import (
+	"regexp"
+	"time"
+)
+
+// Metrics records the outcome of API calls, e.g. as Prometheus counters and
+// histograms. Retried calls are observed once with their total duration.
+type Metrics interface {
+	// ObserveRequest is called after every API call. The endpoint has zone
+	// and other IDs replaced by "{id}" to keep the label cardinality low.
+	// err is nil for successful calls.
+	ObserveRequest(method, endpoint string, statusCode int, err error, d time.Duration)
+}
+
+// WithMetrics reports every API call to m
+func WithMetrics(m Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+var numericSegment = regexp.MustCompile(`/[0-9]+(\.json)?$`)
+
+// endpointLabel replaces the trailing ID of an endpoint path with "{id}"
+func endpointLabel(file string) string {
+	return numericSegment.ReplaceAllString(file, "/{id}$1")
+}
+
+func (c *Client) observe(method, file string, statusCode int, err error, start time.Time) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.ObserveRequest(method, endpointLabel(file), statusCode, err, c.clock.Now().Sub(start))
+}
@@ -0,0 +1,42 @@
+package keycdn
+
+import "net/http"
+
+// Middleware wraps the round tripper used for API requests, e.g. to inject
+// headers, record metrics or implement custom authentication
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WithMiddleware adds middlewares to the request chain. The first middleware
+// is the outermost one, i.e. it sees the request first and the response
+// last. Each attempt of a retried request passes the chain again.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// wrapMiddleware returns the HTTP client with all middlewares applied to its
+// transport
+func (c *Client) wrapMiddleware() *http.Client {
+	if len(c.middleware) == 0 {
+		return c.http
+	}
+	hc := *c.client()
+	rt := hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	hc.Transport = rt
+	return &hc
+}
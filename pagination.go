@@ -0,0 +1,129 @@
+package keycdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// pageSize is the number of items requested per page from list endpoints
+const pageSize = 100
+
+// Iterator iterates over the items of a list endpoint. Pages are fetched
+// lazily while iterating:
+//
+//	it := c.ZoneIterator(ctx)
+//	for it.Next() {
+//		zone := it.Value()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+type Iterator[T any] struct {
+	// fetch returns the next page and whether more pages may follow
+	fetch func() ([]T, bool, error)
+	buf   []T
+	cur   T
+	more  bool
+	err   error
+}
+
+// NewIterator returns an iterator over items which reports err once the
+// items are consumed. It is meant for fake implementations of API.
+func NewIterator[T any](items []T, err error) *Iterator[T] {
+	done := false
+	fetch := func() ([]T, bool, error) {
+		if done {
+			return nil, false, err
+		}
+		done = true
+		return items, true, nil
+	}
+	return &Iterator[T]{fetch: fetch, more: true}
+}
+
+// Next advances to the next item and returns false once all items were
+// consumed or an error occurred
+func (it *Iterator[T]) Next() bool {
+	for len(it.buf) == 0 {
+		if !it.more || it.err != nil {
+			return false
+		}
+		it.buf, it.more, it.err = it.fetch()
+		if it.err != nil {
+			return false
+		}
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Value returns the current item
+func (it *Iterator[T]) Value() T {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// listIterator iterates over the items stored under key of a paginated list
+// endpoint and converts them with conv. Pages are requested until one
+// contains fewer than pageSize items or is empty. Endpoints which ignore the
+// paging parameters return the same page again, so paging also stops at a
+// page starting with the same item as the previous one.
+func listIterator[R, T any](ctx context.Context, c *Client, file string, args map[string]string, key string, conv func(R) T) *Iterator[T] {
+	page := 0
+	var prevFirst []byte
+	fetch := func() ([]T, bool, error) {
+		page++
+		pageArgs := make(map[string]string, len(args)+2)
+		for k, v := range args {
+			pageArgs[k] = v
+		}
+		pageArgs["page"] = strconv.Itoa(page)
+		pageArgs["limit"] = strconv.Itoa(pageSize)
+
+		b, err := c.get(ctx, file, pageArgs)
+		if err != nil {
+			return nil, false, err
+		}
+		var lr dataResponse[[]R]
+		err = c.unmarshal(file, b, &lr)
+		if err != nil {
+			return nil, false, err
+		}
+		if lr.Status != "" && lr.Status != "success" {
+			return nil, false, statusError(file, lr.response, "Failed to list %s", key)
+		}
+		pageItems, found := lr.Data[key]
+		if !found {
+			if page == 1 {
+				return nil, false, fmt.Errorf("%s not found in data", key)
+			}
+			return nil, false, nil
+		}
+		if len(pageItems) == 0 {
+			return nil, false, nil
+		}
+		first, err := json.Marshal(pageItems[0])
+		if err != nil {
+			return nil, false, err
+		}
+		if bytes.Equal(first, prevFirst) {
+			return nil, false, nil
+		}
+		prevFirst = first
+
+		items := make([]T, 0, len(pageItems))
+		for _, item := range pageItems {
+			items = append(items, conv(item))
+		}
+		return items, len(pageItems) == pageSize, nil
+	}
+	return &Iterator[T]{fetch: fetch, more: true}
+}
@@ -0,0 +1,204 @@
+package keycdn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// zonePage serves count zones, paginated unless ignorePaging is set
+func zonePage(count int, ignorePaging bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.FormValue("page"))
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		start, end := 0, count
+		if !ignorePaging {
+			start = (page - 1) * limit
+			end = start + limit
+			if start > count {
+				start = count
+			}
+			if end > count {
+				end = count
+			}
+		}
+		zones := []map[string]string{}
+		for i := start; i < end; i++ {
+			zones = append(zones, map[string]string{"id": strconv.Itoa(i + 1), "name": "zone" + strconv.Itoa(i+1)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   map[string]interface{}{"zones": zones},
+		})
+	}
+}
+
+func TestListIteratorPaging(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		count        int
+		ignorePaging bool
+		wantRequests int32
+	}{
+		{name: "single partial page", count: 42, wantRequests: 1},
+		{name: "exact multiple of page size", count: 2 * pageSize, wantRequests: 3},
+		{name: "empty account", count: 0, wantRequests: 1},
+		{name: "paging ignored, full page", count: pageSize, ignorePaging: true, wantRequests: 2},
+		{name: "paging ignored, more than a page", count: 3 * pageSize, ignorePaging: true, wantRequests: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int32
+			handler := zonePage(tc.count, tc.ignorePaging)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				handler(w, r)
+			}))
+			defer ts.Close()
+
+			c, err := New("key", WithBaseURL(ts.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			zones, err := c.Zones(ctx)
+			if err != nil {
+				t.Fatalf("Zones() failed: %s", err)
+			}
+			if len(zones) != tc.count {
+				t.Errorf("got %d zones, want %d", len(zones), tc.count)
+			}
+			if got := atomic.LoadInt32(&requests); got != tc.wantRequests {
+				t.Errorf("got %d requests, want %d", got, tc.wantRequests)
+			}
+		})
+	}
+}
+
+// countingServer serves handler and counts the requests
+func countingServer(t *testing.T, handler http.HandlerFunc) (*Client, *int32) {
+	t.Helper()
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	c, err := New("key", WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, &requests
+}
+
+func TestZoneIteratorFetchesLazily(t *testing.T) {
+	c, requests := countingServer(t, zonePage(2*pageSize+50, false))
+
+	it := c.ZoneIterator(context.Background())
+	if n := atomic.LoadInt32(requests); n != 0 {
+		t.Fatalf("%d requests before the first Next, want 0", n)
+	}
+	var ids []uint64
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+		// a page is requested when the previous one is consumed
+		if want := int32(len(ids)-1)/pageSize + 1; atomic.LoadInt32(requests) != want {
+			t.Fatalf("%d requests after %d zones, want %d", atomic.LoadInt32(requests), len(ids), want)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2*pageSize+50 || ids[0] != 1 || ids[len(ids)-1] != 2*pageSize+50 {
+		t.Errorf("got %d zones from %v to %v, want zones 1 to %d", len(ids), ids[0], ids[len(ids)-1], 2*pageSize+50)
+	}
+}
+
+func TestZoneIteratorStopEarly(t *testing.T) {
+	c, requests := countingServer(t, zonePage(3*pageSize, false))
+
+	it := c.ZoneIterator(context.Background())
+	for i := 0; i < 10 && it.Next(); i++ {
+	}
+	if it.Value().ID != 10 {
+		t.Errorf("current zone = %d, want 10", it.Value().ID)
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("%d requests for 10 zones, want 1", n)
+	}
+}
+
+func TestZoneIteratorError(t *testing.T) {
+	pages := zonePage(3*pageSize, false)
+	c, requests := countingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("page") == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"status":"error","description":"database unavailable"}`)
+			return
+		}
+		pages(w, r)
+	})
+
+	it := c.ZoneIterator(context.Background())
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if n != pageSize {
+		t.Errorf("got %d zones before the error, want the %d of the first page", n, pageSize)
+	}
+	var apiErr *APIError
+	if !errors.As(it.Err(), &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Err = %v, want the 500 of the second page", it.Err())
+	}
+	// the iterator stays stopped
+	if it.Next() {
+		t.Error("Next after an error = true, want false")
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("%d requests, want 2", got)
+	}
+}
+
+func TestEdgeRuleIteratorFiltersByZone(t *testing.T) {
+	c, _ := countingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"status":"success","data":{"edgerules":[{"id":"7","zone_id":%q,"name":"redirect"}]}}`, r.FormValue("zone_id"))
+	})
+
+	it := c.EdgeRuleIterator(context.Background(), 42)
+	if !it.Next() {
+		t.Fatalf("no edge rule, err = %v", it.Err())
+	}
+	if rule := it.Value(); rule.ID != 7 || rule.ZoneID != 42 {
+		t.Errorf("edge rule = %+v, want rule 7 of zone 42", rule)
+	}
+	if it.Next() {
+		t.Errorf("unexpected edge rule %+v", it.Value())
+	}
+}
+
+func TestNewIterator(t *testing.T) {
+	failure := errors.New("boom")
+	it := NewIterator([]string{"a", "b"}, failure)
+	var got []string
+	for it.Next() {
+		got = append(got, it.Value())
+		if it.Err() != nil {
+			t.Fatalf("Err = %v before the items were consumed", it.Err())
+		}
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("items = %v, want [a b]", got)
+	}
+	if it.Err() != failure {
+		t.Errorf("Err = %v, want %v", it.Err(), failure)
+	}
+}
@@ -0,0 +1,148 @@
+package keycdn_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+func TestPurgeChangedURLs(t *testing.T) {
+	ctx := context.Background()
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	purged, err := c.PurgeChangedURLs(ctx, z.ID, map[string]time.Time{
+		"https://cdn.example.com/b.css":  since.Add(time.Second),
+		"https://cdn.example.com/a.js":   since.Add(time.Hour),
+		"https://cdn.example.com/c.png":  since,
+		"https://cdn.example.com/d.html": since.Add(-time.Hour),
+	}, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only URLs modified after since are purged, in sorted order
+	want := []string{"https://cdn.example.com/a.js", "https://cdn.example.com/b.css"}
+	if !reflect.DeepEqual(purged, want) {
+		t.Errorf("PurgeChangedURLs = %v, want %v", purged, want)
+	}
+	if got := s.Fake.PurgedURLs(z.ID); !reflect.DeepEqual(got, want) {
+		t.Errorf("purged URLs = %v, want %v", got, want)
+	}
+
+	// nothing changed, nothing is sent
+	purged, err = c.PurgeChangedURLs(ctx, z.ID, map[string]time.Time{"https://cdn.example.com/c.png": since}, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged == nil || len(purged) != 0 {
+		t.Errorf("PurgeChangedURLs without changes = %#v, want an empty list", purged)
+	}
+	if n := s.Requests("/zones/purgeurl/1.json"); n != 1 {
+		t.Errorf("%d purge requests, want 1", n)
+	}
+}
+
+func TestPurgeChangedURLsUnknownZone(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := c.PurgeChangedURLs(context.Background(), 42, map[string]time.Time{"https://cdn.example.com/a.js": time.Now()}, time.Time{})
+	if !errors.Is(err, keycdn.ErrZoneNotFound) || purged != nil {
+		t.Errorf("PurgeChangedURLs = %v, %v, want ErrZoneNotFound", purged, err)
+	}
+	if n := s.Requests("/zones/purgeurl/42.json"); n != 0 {
+		t.Errorf("%d purge requests for an unknown zone, want 0", n)
+	}
+}
+
+func TestPurgeZonePrefix(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefixes := []string{"zone-1.kxcdn.com/static/", "zone-1.kxcdn.com/img/*"}
+	if err := c.PurgeZonePrefix(context.Background(), z.ID, prefixes); err != nil {
+		t.Fatal(err)
+	}
+	// a missing trailing "*" is added, an existing one is kept
+	want := []keycdntest.Purge{{ZoneID: z.ID, Kind: "prefix", Items: []string{"zone-1.kxcdn.com/static/*", "zone-1.kxcdn.com/img/*"}}}
+	if got := s.Fake.Purges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("purges = %+v, want %+v", got, want)
+	}
+	// prefixes are not purged as URLs
+	if urls := s.Fake.PurgedURLs(z.ID); len(urls) != 0 {
+		t.Errorf("purged URLs = %v, want none", urls)
+	}
+
+	// the fake records the same prefixes as the server
+	f := keycdntest.NewFake()
+	f.SeedZone(keycdn.Zone{Name: "assets"})
+	if err := f.PurgeZonePrefix(context.Background(), z.ID, prefixes); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Purges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("fake purges = %+v, want %+v", got, want)
+	}
+}
+
+func TestPurgeZonePrefixUnknownZone(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.PurgeZonePrefix(context.Background(), 42, []string{"zone-42.kxcdn.com/"}); !errors.Is(err, keycdn.ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+	if purges := s.Fake.Purges(); len(purges) != 0 {
+		t.Errorf("purges = %+v, want none", purges)
+	}
+}
+
+func TestExpandURLVariants(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		base    string
+		queries []string
+		want    []string
+	}{
+		{"queries", "https://cdn.example.com/img.jpg", []string{"w=100", "w=200"},
+			[]string{"https://cdn.example.com/img.jpg?w=100", "https://cdn.example.com/img.jpg?w=200"}},
+		{"leading question mark", "https://cdn.example.com/img.jpg", []string{"?w=100"},
+			[]string{"https://cdn.example.com/img.jpg?w=100"}},
+		{"empty query is the base", "https://cdn.example.com/img.jpg", []string{"", "w=100"},
+			[]string{"https://cdn.example.com/img.jpg", "https://cdn.example.com/img.jpg?w=100"}},
+		{"base with query", "https://cdn.example.com/img.jpg?v=2", []string{"w=100", ""},
+			[]string{"https://cdn.example.com/img.jpg?v=2&w=100", "https://cdn.example.com/img.jpg?v=2"}},
+		{"duplicates removed in order", "https://cdn.example.com/img.jpg", []string{"w=200", "?w=100", "w=100", "?w=200"},
+			[]string{"https://cdn.example.com/img.jpg?w=200", "https://cdn.example.com/img.jpg?w=100"}},
+		{"no queries", "https://cdn.example.com/img.jpg", nil, []string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := keycdn.ExpandURLVariants(tc.base, tc.queries); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ExpandURLVariants(%q, %q) = %q, want %q", tc.base, tc.queries, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package keycdn_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	c, clock := newRetryClient(t, s, keycdn.WithRateLimit(2, 3))
+	start := clock.Now()
+
+	// the limit is shared by all methods
+	calls := []func() error{
+		func() error { _, err := c.Zone(ctx, z.ID); return err },
+		func() error { _, err := c.Zones(ctx); return err },
+		func() error { return c.PurgeZoneCache(ctx, z.ID) },
+		func() error { _, err := c.Zone(ctx, z.ID); return err },
+		func() error { _, err := c.Zones(ctx); return err },
+	}
+	var sent []time.Duration
+	for _, call := range calls {
+		if err := call(); err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, clock.Now().Sub(start))
+	}
+	// a burst of 3 requests, then one request every 500ms
+	if want := []time.Duration{0, 0, 0, 500 * time.Millisecond, time.Second}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("requests sent at %v, want %v", sent, want)
+	}
+	if n := s.Requests("/zones/1.json") + s.Requests("/zones.json") + s.Requests("/zones/purge/1.json"); n != len(calls) {
+		t.Errorf("%d requests sent, want %d", n, len(calls))
+	}
+}
+
+func TestRateLimitRespectsContext(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	// the clock does not advance, so no token is added
+	clock := keycdntest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL), keycdn.WithClock(clock), keycdn.WithRateLimit(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Zone(context.Background(), z.ID); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Zone(ctx, z.ID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if n := s.Requests("/zones/1.json"); n != 1 {
+		t.Errorf("%d requests sent, want 1", n)
+	}
+}
+
+func TestRateLimitEvery(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	// 20 requests per minute
+	c, clock := newRetryClient(t, s, keycdn.WithRateLimitEvery(3*time.Second, 1))
+	start := clock.Now()
+
+	var sent []time.Duration
+	for i := 0; i < 3; i++ {
+		if _, err := c.Zone(context.Background(), z.ID); err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, clock.Now().Sub(start))
+	}
+	if want := []time.Duration{0, 3 * time.Second, 6 * time.Second}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("requests sent at %v, want %v", sent, want)
+	}
+	if n := s.Requests("/zones/1.json"); n != 3 {
+		t.Errorf("%d requests sent, want 3", n)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	for name, opt := range map[string]keycdn.Option{
+		"WithRateLimit":      keycdn.WithRateLimit(0, 1),
+		"WithRateLimitEvery": keycdn.WithRateLimitEvery(0, 1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := keycdntest.NewServer()
+			defer s.Close()
+			z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+			// a limit set before is replaced
+			c, clock := newRetryClient(t, s, keycdn.WithRateLimit(1, 1), opt)
+
+			for i := 0; i < 5; i++ {
+				if _, err := c.Zone(context.Background(), z.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(clock.Waits()) != 0 {
+				t.Errorf("waited %v without a rate limit", clock.Waits())
+			}
+		})
+	}
+}
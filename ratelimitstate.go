@@ -0,0 +1,97 @@
+package keycdn
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitState is the rate limit information reported by the API with
+// the most recent response
+type RateLimitState struct {
+	Limit     int
+	Remaining int
+	// Reset is when the limit is replenished, zero if not reported
+	Reset time.Time
+	// Updated is when the state was received
+	Updated time.Time
+}
+
+// rateLimitTracker stores the latest RateLimitState. It is shared by all
+// copies of a Client.
+type rateLimitTracker struct {
+	mu    sync.Mutex
+	state RateLimitState
+	seen  bool
+}
+
+// header names used for rate limit information, the API has used both
+// spellings
+var (
+	limitHeaders     = []string{"X-Rate-Limit-Limit", "X-RateLimit-Limit"}
+	remainingHeaders = []string{"X-Rate-Limit-Remaining", "X-RateLimit-Remaining"}
+	resetHeaders     = []string{"X-Rate-Limit-Reset", "X-RateLimit-Reset"}
+)
+
+func firstHeader(h http.Header, names []string) string {
+	for _, n := range names {
+		if v := h.Get(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// update records the rate limit headers of a response, if present
+func (t *rateLimitTracker) update(h http.Header, now time.Time) {
+	if t == nil {
+		return
+	}
+	state, ok := parseRateLimit(h, now)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state = state
+	t.seen = true
+}
+
+// parseRateLimit returns the rate limit state reported by the headers of a
+// response received at now. The bool is false if the headers are missing.
+func parseRateLimit(h http.Header, now time.Time) (RateLimitState, bool) {
+	limit, err := strconv.Atoi(firstHeader(h, limitHeaders))
+	if err != nil {
+		return RateLimitState{}, false
+	}
+	remaining, err := strconv.Atoi(firstHeader(h, remainingHeaders))
+	if err != nil {
+		return RateLimitState{}, false
+	}
+	state := RateLimitState{
+		Limit:     limit,
+		Remaining: remaining,
+		Updated:   now,
+	}
+	if reset, err := strconv.ParseInt(firstHeader(h, resetHeaders), 10, 64); err == nil {
+		// large values are Unix timestamps, small ones seconds from now
+		if reset > 1e9 {
+			state.Reset = time.Unix(reset, 0)
+		} else {
+			state.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return state, true
+}
+
+// RateLimitState returns the rate limit information of the most recent
+// response which carried any. The bool is false if none was received yet.
+func (c *Client) RateLimitState() (RateLimitState, bool) {
+	if c.rateLimits == nil {
+		return RateLimitState{}, false
+	}
+	c.rateLimits.mu.Lock()
+	defer c.rateLimits.mu.Unlock()
+	return c.rateLimits.state, c.rateLimits.seen
+}
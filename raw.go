@@ -0,0 +1,115 @@
+package keycdn
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ResponseMeta describes the response to a request
+type ResponseMeta struct {
+	// StatusCode is the HTTP status code
+	StatusCode int
+	// Status and Description are the fields of the same name of the
+	// response body, if any
+	Status      string
+	Description string
+	// RateLimit is the rate limit state reported with the response, if any
+	RateLimit *RateLimitState
+	// RequestID identifies the request in support cases, if the API sent
+	// one
+	RequestID string
+	Header    http.Header
+}
+
+type responseMetaKey struct{}
+
+// WithResponseMeta returns a context which makes calls store the meta data of
+// their response in meta, e.g. to log the description of a successful call:
+//
+//	var meta keycdn.ResponseMeta
+//	zones, err := c.Zones(keycdn.WithResponseMeta(ctx, &meta))
+//	log.Printf("HTTP %d: %s", meta.StatusCode, meta.Description)
+//
+// Calls which send several requests, e.g. to fetch all pages of a list,
+// store the meta data of the last response. Cached responses leave meta
+// untouched.
+func WithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	return context.WithValue(ctx, responseMetaKey{}, meta)
+}
+
+func wantsResponseMeta(ctx context.Context) bool {
+	meta, ok := ctx.Value(responseMetaKey{}).(*ResponseMeta)
+	return ok && meta != nil
+}
+
+// storeResponseMeta stores the meta data of res in the ResponseMeta of ctx,
+// if any
+func storeResponseMeta(ctx context.Context, res result, now time.Time) {
+	meta, ok := ctx.Value(responseMetaKey{}).(*ResponseMeta)
+	if !ok || meta == nil {
+		return
+	}
+	if m := newResponseMeta(res, now); m != nil {
+		*meta = *m
+	}
+}
+
+// newResponseMeta returns the meta data of the response of res, or nil if
+// no response was received
+func newResponseMeta(res result, now time.Time) *ResponseMeta {
+	if res.statusCode == 0 {
+		return nil
+	}
+	meta := &ResponseMeta{
+		StatusCode: res.statusCode,
+		RequestID:  res.header.Get("X-Request-Id"),
+		Header:     res.header,
+	}
+	if state, ok := parseRateLimit(res.header, now); ok {
+		meta.RateLimit = &state
+	}
+	var resp response
+	if err := json.Unmarshal(res.body, &resp); err == nil {
+		meta.Status = string(resp.Status)
+		meta.Description = resp.Description
+	}
+	return meta
+}
+
+// Do sends a request to an endpoint which has no dedicated method, e.g.
+//
+//	b, meta, err := c.Do(ctx, "GET", "/zonealiases.json", nil, nil)
+//
+// path is relative to the base URL. A body of type url.Values is sent form
+// encoded, any other body as JSON, a nil body is not sent. Authentication, retries, rate limiting and
+// error handling work like for all other methods, responses with a status
+// other than success fail with an *APIError. The raw response body is
+// returned, meta is nil if no response was received.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body interface{}) ([]byte, *ResponseMeta, error) {
+	var res result
+	if method == "GET" {
+		u := c.Base + path + "?" + query.Encode()
+		res = c.do(ctx, method, path, u, nil, http.Header{}, nil)
+	} else {
+		enc := encodingJSON
+		if _, ok := body.(url.Values); ok {
+			enc = encodingForm
+		}
+		res = c.request(ctx, method, path, query, body, enc)
+	}
+	meta := newResponseMeta(res, c.clock.Now())
+	if res.err != nil {
+		return res.body, meta, res.err
+	}
+	if meta != nil && meta.Status != "" && meta.Status != "success" {
+		resp := response{Status: status(meta.Status), Description: meta.Description}
+		return res.body, meta, statusError(path, resp, "Failed to %s %s", method, path)
+	}
+	if meta != nil {
+		c.warn(path, meta.Description)
+	}
+	return res.body, meta, nil
+}
@@ -0,0 +1,241 @@
+package keycdn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// reportArgs returns the common query parameters of the report endpoints
+func reportArgs(zoneID uint64, from, to time.Time) map[string]string {
+	args := make(map[string]string, 5)
+	args["zone_id"] = strconv.FormatUint(zoneID, 10)
+	args["start"] = strconv.Itoa(int(from.Unix()))
+	args["end"] = strconv.Itoa(int(to.Unix()))
+	return args
+}
+
+// URLStat is the number of requests for a single URL
+type URLStat struct {
+	URL      string
+	Requests uint64
+}
+
+type urlStatResp struct {
+	URL    string     `json:"url"`
+	Amount flexString `json:"amount"`
+}
+
+type topURLsResponse struct {
+	response
+	Data map[string][]urlStatResp `json:"data"`
+}
+
+// TopURLs returns the most requested URLs of a zone in the given interval,
+// ordered by the number of requests. At most limit entries are returned,
+// a limit <= 0 returns all entries reported by the API. It fails if the
+// request count of an entry is not a number.
+func (c *Client) TopURLs(ctx context.Context, zoneID uint64, from, to time.Time, limit int) ([]URLStat, error) {
+	args := reportArgs(zoneID, from, to)
+	if limit > 0 {
+		args["limit"] = strconv.Itoa(limit)
+	}
+	var tr topURLsResponse
+	if err := c.getJSON(ctx, "/reports/topurls.json", args, &tr); err != nil {
+		return nil, err
+	}
+	if tr.Status != "" && tr.Status != "success" {
+		return nil, statusError("/reports/topurls.json", tr.response, "Failed to get top URLs of Zone %d", zoneID)
+	}
+	if _, found := tr.Data["stats"]; !found {
+		return nil, ErrStatsMissing
+	}
+	stats := make([]URLStat, 0, len(tr.Data["stats"]))
+	for _, s := range tr.Data["stats"] {
+		n, ok := parseAmount(string(s.Amount))
+		if !ok {
+			return nil, fmt.Errorf("Failed to get top URLs of Zone %d: invalid request count %q of %s", zoneID, s.Amount, s.URL)
+		}
+		stats = append(stats, URLStat{URL: s.URL, Requests: n})
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Requests > stats[j].Requests
+	})
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+// ProcessedImages returns the number of images optimized or transformed by
+// the image processing of a zone in the given interval. It returns ErrNoData
+// if the API reported no data for the interval.
+func (c *Client) ProcessedImages(ctx context.Context, zoneID uint64, from, to time.Time) (uint64, error) {
+	args := reportArgs(zoneID, from, to)
+	args["interval"] = "hour"
+	var tr trafficResponse
+	if err := c.getJSON(ctx, "/reports/image-processing.json", args, &tr); err != nil {
+		return 0, err
+	}
+	if tr.Status != "" && tr.Status != "success" {
+		return 0, statusError("/reports/image-processing.json", tr.response, "Failed to get processed images of Zone %d", zoneID)
+	}
+	if _, found := tr.Data["stats"]; !found {
+		return 0, ErrStatsMissing
+	}
+	if len(tr.Data["stats"]) == 0 {
+		return 0, ErrNoData
+	}
+	var sum uint64
+	for _, a := range tr.Data["stats"] {
+		sum += a.Count()
+	}
+	return sum, nil
+}
+
+// CacheHitRatio returns the share of cache hits among all cacheable requests
+// of a zone in the given interval. It returns 0 if there was no traffic.
+func (c *Client) CacheHitRatio(ctx context.Context, zoneID uint64, from, to time.Time) (float64, error) {
+	stats, err := c.Stats(ctx, zoneID, from, to)
+	if err != nil && !errors.Is(err, ErrNoData) {
+		return 0, err
+	}
+	return SummarizeStats(stats).HitRatio, nil
+}
+
+// share returns part/total or 0 if total is 0
+func share(part, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// StatsSummary holds the raw request counts of a zone together with the
+// ratios derived from them
+type StatsSummary struct {
+	CacheHit  uint64
+	CacheMiss uint64
+	Success   uint64
+	Error     uint64
+	// HitRatio is CacheHit / (CacheHit + CacheMiss)
+	HitRatio float64
+	// ErrorRate is Error / (Success + Error)
+	ErrorRate float64
+}
+
+// StatsSummary returns the request counts and derived ratios of a zone in
+// the given interval. The ratios are 0 if there was no traffic.
+func (c *Client) StatsSummary(ctx context.Context, zoneID uint64, from, to time.Time) (StatsSummary, error) {
+	stats, err := c.Stats(ctx, zoneID, from, to)
+	if err != nil && !errors.Is(err, ErrNoData) {
+		return StatsSummary{}, err
+	}
+	return SummarizeStats(stats), nil
+}
+
+// SummarizeStats derives a StatsSummary from the statistics returned by
+// Stats. The ratios are 0 if there was no traffic.
+func SummarizeStats(stats map[string]uint64) StatsSummary {
+	s := StatsSummary{
+		CacheHit:  stats["totalcachehit"],
+		CacheMiss: stats["totalcachemiss"],
+		Success:   stats["totalsuccess"],
+		Error:     stats["totalerror"],
+	}
+	s.HitRatio = share(s.CacheHit, s.CacheHit+s.CacheMiss)
+	s.ErrorRate = share(s.Error, s.Success+s.Error)
+	return s
+}
+
+// statsParallelism bounds the number of concurrent requests of StatsMulti.
+// It is kept low to stay within the API rate limits.
+const statsParallelism = 4
+
+// StatsMulti fetches the stats of several zones concurrently. Zones without
+// data in the interval get empty stats. The results of
+// all zones that could be fetched are returned together with the first error
+// encountered, if any.
+func (c *Client) StatsMulti(ctx context.Context, zoneIDs []uint64, from, to time.Time) (map[uint64]map[string]uint64, error) {
+	ret := make(map[uint64]map[string]uint64, len(zoneIDs))
+	var mu sync.Mutex
+	var firstErr error
+
+	sem := make(chan struct{}, statsParallelism)
+	var wg sync.WaitGroup
+	for _, id := range zoneIDs {
+		// stop waiting for a free slot once the context is done
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(id uint64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			stats, err := c.Stats(ctx, id, from, to)
+			if errors.Is(err, ErrNoData) {
+				err = nil
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("Zone %d: %w", id, err)
+				}
+				return
+			}
+			ret[id] = stats
+		}(id)
+	}
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return ret, firstErr
+}
+
+// Usage is the aggregated usage of the whole account
+type Usage struct {
+	// Traffic is the transferred volume in bytes
+	Traffic  uint64
+	Requests uint64
+}
+
+// Usage returns the usage of the whole account in the given interval as
+// reported by the account level report, which is what KeyCDN bills. It
+// returns ErrNoData if the API reported no data for the interval.
+func (c *Client) Usage(ctx context.Context, from, to time.Time) (Usage, error) {
+	args := map[string]string{
+		"start": strconv.Itoa(int(from.Unix())),
+		"end":   strconv.Itoa(int(to.Unix())),
+	}
+	var ur stateStatResponse
+	if err := c.getJSON(ctx, "/reports/usage.json", args, &ur); err != nil {
+		return Usage{}, err
+	}
+	if ur.Status != "" && ur.Status != "success" {
+		return Usage{}, statusError("/reports/usage.json", ur.response, "Failed to get usage")
+	}
+	if _, found := ur.Data["stats"]; !found {
+		return Usage{}, ErrStatsMissing
+	}
+	if len(ur.Data["stats"]) == 0 {
+		return Usage{}, ErrNoData
+	}
+	var u Usage
+	for _, a := range ur.Data["stats"] {
+		u.Traffic += a.Get("traffic")
+		u.Requests += a.Get("requests")
+	}
+	return u, nil
+}
@@ -0,0 +1,297 @@
+package keycdn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheHitRatio(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		want float64
+	}{
+		{"no traffic", `{"status":"success","data":{"stats":[]}}`, 0},
+		{"no requests", `{"status":"success","data":{"stats":[{"totalcachehit":"0","totalcachemiss":"0"}]}}`, 0},
+		{"hits and misses", `{"status":"success","data":{"stats":[
+			{"totalcachehit":"2","totalcachemiss":"1"},
+			{"totalcachehit":"1","totalcachemiss":"0"}
+		]}}`, 0.75},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := newTestServer(t, tc.body)
+			got, err := c.CacheHitRatio(context.Background(), 1, time.Now().Add(-time.Hour), time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("CacheHitRatio = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// assertStatusError checks that err is the *APIError of a 200 response
+// reporting an error
+func assertStatusError(t *testing.T, err error, endpoint, op string) {
+	t.Helper()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("%s: err = %v, want *APIError", endpoint, err)
+	}
+	if apiErr.Endpoint != endpoint || apiErr.Op != op || apiErr.Description != "Zone not found" {
+		t.Errorf("err = %+v, want endpoint %s and op %q", apiErr, endpoint, op)
+	}
+	if errors.Is(err, ErrStatsMissing) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("%s: err = %v, want it to match ErrNotFound only", endpoint, err)
+	}
+}
+
+func TestReportsCheckStatus(t *testing.T) {
+	ctx := context.Background()
+	from, to := time.Unix(1700000000, 0), time.Unix(1700003600, 0)
+	c, _ := newTestServer(t, `{"status":"error","description":"Zone not found"}`)
+
+	_, err := c.Traffic(ctx, 1, from, to)
+	assertStatusError(t, err, "/reports/traffic.json", "Failed to get traffic of Zone 1")
+	_, err = c.Stats(ctx, 1, from, to)
+	assertStatusError(t, err, "/reports/statestats.json", "Failed to get stats of Zone 1")
+	_, err = c.TopURLs(ctx, 1, from, to, 10)
+	assertStatusError(t, err, "/reports/topurls.json", "Failed to get top URLs of Zone 1")
+	_, err = c.ProcessedImages(ctx, 1, from, to)
+	assertStatusError(t, err, "/reports/image-processing.json", "Failed to get processed images of Zone 1")
+}
+
+func TestTopURLs(t *testing.T) {
+	c, ts := newTestServer(t, `{"status":"success","data":{"stats":[
+		{"url":"/a.css","amount":"3"},
+		{"url":"/b.js","amount":12},
+		{"url":"/c.png","amount":"7"}
+	]}}`)
+	got, err := c.TopURLs(context.Background(), 1, time.Unix(0, 0), time.Unix(3600, 0), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []URLStat{{URL: "/b.js", Requests: 12}, {URL: "/c.png", Requests: 7}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopURLs = %+v, want %+v", got, want)
+	}
+	if limit := ts.last(t).Query.Get("limit"); limit != "2" {
+		t.Errorf("limit = %q, want 2", limit)
+	}
+}
+
+func TestTopURLsInvalidAmount(t *testing.T) {
+	c, _ := newTestServer(t, `{"status":"success","data":{"stats":[
+		{"url":"/a.css","amount":"3"},
+		{"url":"/b.js","amount":"n/a"}
+	]}}`)
+	got, err := c.TopURLs(context.Background(), 1, time.Unix(0, 0), time.Unix(3600, 0), 0)
+	if err == nil || !strings.Contains(err.Error(), `"n/a"`) || !strings.Contains(err.Error(), "/b.js") {
+		t.Errorf("err = %v, want an error naming the invalid entry", err)
+	}
+	if got != nil {
+		t.Errorf("TopURLs = %+v, want no partial result", got)
+	}
+}
+
+func TestProcessedImages(t *testing.T) {
+	c, _ := newTestServer(t, `{"status":"success","data":{"stats":[
+		{"amount":"5","timestamp":"1700000000"},
+		{"amount":"8","timestamp":"1700003600"}
+	]}}`)
+	got, err := c.ProcessedImages(context.Background(), 1, time.Unix(1700000000, 0), time.Unix(1700007200, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 13 {
+		t.Errorf("ProcessedImages = %d, want 13", got)
+	}
+}
+
+// statsServer answers stats requests with the zone ID as cache hits, holding
+// each request until release is closed. full is closed once
+// statsParallelism requests are in flight.
+type statsServer struct {
+	mu                      sync.Mutex
+	inflight, max, requests int
+	full, release           chan struct{}
+}
+
+func newStatsServer(t *testing.T, respond func(w http.ResponseWriter, zoneID string) bool) (*Client, *statsServer) {
+	t.Helper()
+	ss := &statsServer{full: make(chan struct{}), release: make(chan struct{})}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ss.mu.Lock()
+		ss.requests++
+		ss.inflight++
+		if ss.inflight > ss.max {
+			ss.max = ss.inflight
+		}
+		if ss.inflight == statsParallelism && ss.max == statsParallelism {
+			close(ss.full)
+		}
+		ss.mu.Unlock()
+		defer func() {
+			ss.mu.Lock()
+			ss.inflight--
+			ss.mu.Unlock()
+		}()
+
+		<-ss.release
+		zoneID := r.FormValue("zone_id")
+		if respond != nil && respond(w, zoneID) {
+			return
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"stats":[{"totalcachehit":"%s"}]}}`, zoneID)
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() {
+		select {
+		case <-ss.release:
+		default:
+			close(ss.release)
+		}
+	})
+	c, err := New("key", WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, ss
+}
+
+// counts returns the maximum number of concurrent requests and the number
+// of all requests
+func (ss *statsServer) counts() (max, requests int) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.max, ss.requests
+}
+
+// waitFull waits until statsParallelism requests are in flight and gives
+// excess requests the chance to arrive
+func (ss *statsServer) waitFull(t *testing.T) {
+	t.Helper()
+	select {
+	case <-ss.full:
+	case <-time.After(5 * time.Second):
+		max, _ := ss.counts()
+		t.Fatalf("at most %d requests in flight, want %d", max, statsParallelism)
+	}
+	time.Sleep(20 * time.Millisecond)
+}
+
+// zoneIDs returns the IDs 1 to n
+func zoneIDs(n int) []uint64 {
+	ids := make([]uint64, n)
+	for i := range ids {
+		ids[i] = uint64(i + 1)
+	}
+	return ids
+}
+
+type statsResult struct {
+	stats map[uint64]map[string]uint64
+	err   error
+}
+
+func TestStatsMultiBoundsConcurrency(t *testing.T) {
+	c, ss := newStatsServer(t, nil)
+	done := make(chan statsResult)
+	go func() {
+		stats, err := c.StatsMulti(context.Background(), zoneIDs(10), time.Unix(1700000000, 0), time.Unix(1700003600, 0))
+		done <- statsResult{stats, err}
+	}()
+
+	ss.waitFull(t)
+	if max, _ := ss.counts(); max != statsParallelism {
+		t.Errorf("%d concurrent requests, want %d", max, statsParallelism)
+	}
+	close(ss.release)
+	res := <-done
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if len(res.stats) != 10 {
+		t.Fatalf("stats of %d zones, want 10", len(res.stats))
+	}
+	for id, stats := range res.stats {
+		if stats["totalcachehit"] != id {
+			t.Errorf("stats of zone %d = %v, want its own", id, stats)
+		}
+	}
+	if max, requests := ss.counts(); max > statsParallelism || requests != 10 {
+		t.Errorf("%d requests with up to %d at once, want 10 with at most %d", requests, max, statsParallelism)
+	}
+}
+
+func TestStatsMultiPartialResults(t *testing.T) {
+	c, ss := newStatsServer(t, func(w http.ResponseWriter, zoneID string) bool {
+		switch zoneID {
+		case "3":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"status":"error","description":"report unavailable"}`)
+		case "5":
+			fmt.Fprint(w, `{"status":"success","data":{"stats":[]}}`)
+		default:
+			return false
+		}
+		return true
+	})
+	close(ss.release)
+
+	stats, err := c.StatsMulti(context.Background(), zoneIDs(6), time.Unix(1700000000, 0), time.Unix(1700003600, 0))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError || !strings.HasPrefix(err.Error(), "Zone 3: ") {
+		t.Fatalf("err = %v, want the 500 of zone 3", err)
+	}
+	if _, found := stats[3]; found {
+		t.Errorf("stats of the failed zone 3 = %v, want none", stats[3])
+	}
+	// zones without data get empty stats
+	if s, found := stats[5]; !found || len(s) != 0 {
+		t.Errorf("stats of zone 5 without data = %v (found %t), want empty stats", s, found)
+	}
+	for _, id := range []uint64{1, 2, 4, 6} {
+		if stats[id]["totalcachehit"] != id {
+			t.Errorf("stats of zone %d = %v, want its own", id, stats[id])
+		}
+	}
+}
+
+func TestStatsMultiCanceled(t *testing.T) {
+	c, ss := newStatsServer(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan statsResult)
+	go func() {
+		stats, err := c.StatsMulti(ctx, zoneIDs(10), time.Unix(1700000000, 0), time.Unix(1700003600, 0))
+		done <- statsResult{stats, err}
+	}()
+
+	ss.waitFull(t)
+	cancel()
+	var res statsResult
+	select {
+	case res = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("StatsMulti did not return after the context was canceled")
+	}
+	if !errors.Is(res.err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", res.err)
+	}
+	if len(res.stats) != 0 {
+		t.Errorf("stats = %v, want none", res.stats)
+	}
+	// no zone is requested after the cancellation
+	if _, n := ss.counts(); n != statsParallelism {
+		t.Errorf("%d requests, want %d", n, statsParallelism)
+	}
+}
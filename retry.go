@@ -0,0 +1,90 @@
+package keycdn
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetryDelay caps the backoff between two attempts
+const maxRetryDelay = 30 * time.Second
+
+// retryPolicy controls how failed requests are retried
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	// jitter is the fraction by which a delay is randomly shortened
+	jitter float64
+}
+
+// backoff returns the delay before the next attempt after the given number
+// of failed attempts
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	d := p.baseDelay
+	for i := 1; i < attempt && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	if p.jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.jitter * float64(d))
+	}
+	return d
+}
+
+// WithRetryJitter randomly shortens each backoff delay by up to the given
+// fraction, e.g. 0.5 waits between 50% and 100% of the delay. This keeps
+// many clients failing at the same time from retrying in lockstep. The
+// fraction is clamped to [0, 1].
+func WithRetryJitter(fraction float64) Option {
+	return func(c *Client) {
+		if fraction < 0 {
+			fraction = 0
+		}
+		if fraction > 1 {
+			fraction = 1
+		}
+		c.retry.jitter = fraction
+	}
+}
+
+// WithRetryBudget limits the retries of all copies of the client to the
+// given number per interval. Failed requests are not retried while the
+// budget is exhausted, which keeps an outage from being amplified by
+// retries. Unused retries accumulate up to the given number.
+func WithRetryBudget(retries int, per time.Duration) Option {
+	return func(c *Client) {
+		if retries < 1 || per <= 0 {
+			c.retryBudget = nil
+			return
+		}
+		c.retryBudget = newRateLimiter(float64(retries)/per.Seconds(), retries)
+	}
+}
+
+// maxThrottledRetries limits how often a request rejected with 429 Too Many
+// Requests is retried. These retries happen even if WithRetry is not used
+// and do not count against its attempts.
+const maxThrottledRetries = 3
+
+// minThrottleDelay is the delay after a 429 response without Retry-After
+const minThrottleDelay = time.Second
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date. It returns 0 if the value is invalid.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,254 @@
+package keycdn_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+// newRetryClient returns a client of s whose clock advances on every wait
+func newRetryClient(t *testing.T, s *keycdntest.Server, opts ...keycdn.Option) (*keycdn.Client, *keycdntest.Clock) {
+	t.Helper()
+	clock := keycdntest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.AutoAdvance(true)
+	c, err := keycdn.New("key", append([]keycdn.Option{keycdn.WithBaseURL(s.URL), keycdn.WithClock(clock)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, clock
+}
+
+func TestRetryBackoff(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	s.Fail("/zones/1.json", http.StatusBadGateway, 3)
+
+	c, clock := newRetryClient(t, s, keycdn.WithRetry(4, time.Second))
+	if _, err := c.Zone(context.Background(), z.ID); err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	if got := clock.Waits(); !reflect.DeepEqual(got, want) {
+		t.Errorf("backoff = %v, want %v", got, want)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	s.Fail("/zones/1.json", http.StatusServiceUnavailable, 5)
+	c, clock := newRetryClient(t, s, keycdn.WithRetry(3, time.Second))
+
+	_, err := c.Zone(context.Background(), z.ID)
+	var apiErr *keycdn.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want the 503 of the last attempt", err)
+	}
+	if n := s.Requests("/zones/1.json"); n != 3 {
+		t.Errorf("%d requests sent, want 3", n)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(clock.Waits(), want) {
+		t.Errorf("waits = %v, want %v", clock.Waits(), want)
+	}
+}
+
+func TestRetrySkipsClientErrors(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	c, clock := newRetryClient(t, s, keycdn.WithRetry(3, time.Second))
+
+	if _, err := c.Zone(context.Background(), 42); !errors.Is(err, keycdn.ErrZoneNotFound) {
+		t.Fatalf("err = %v, want ErrZoneNotFound", err)
+	}
+	if n := s.Requests("/zones/42.json"); n != 1 || len(clock.Waits()) != 0 {
+		t.Errorf("%d requests sent after waiting %v, want a single request", n, clock.Waits())
+	}
+}
+
+func TestRetrySkipsPost(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	s.Fail("/zones.json", http.StatusBadGateway, 1)
+	c, clock := newRetryClient(t, s, keycdn.WithRetry(3, time.Second))
+
+	if _, err := c.AddZone(context.Background(), keycdn.ZoneCreateRequest{Name: "assets"}); err == nil {
+		t.Fatal("AddZone succeeded, want the 502")
+	}
+	if n := s.Requests("/zones.json"); n != 1 || len(clock.Waits()) != 0 {
+		t.Errorf("%d requests sent after waiting %v, want a single request", n, clock.Waits())
+	}
+	if zones, _ := s.Fake.Zones(context.Background()); len(zones) != 0 {
+		t.Errorf("zones = %v, want none", zones)
+	}
+}
+
+func TestRetryPostWithIdempotencyKey(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	s.Fail("/zones.json", http.StatusBadGateway, 2)
+	c, _ := newRetryClient(t, s, keycdn.WithRetry(3, time.Second), keycdn.WithIdempotencyKeys())
+
+	z, err := c.AddZone(context.Background(), keycdn.ZoneCreateRequest{Name: "assets"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z.Name != "assets" {
+		t.Errorf("AddZone = %+v, want zone assets", z)
+	}
+	if n := s.Requests("/zones.json"); n != 3 {
+		t.Errorf("%d requests sent, want 3", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name       string
+		retryAfter string
+		want       time.Duration
+	}{
+		{"seconds", "7", 7 * time.Second},
+		{"date", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"missing", "", time.Second},
+		{"invalid", "soon", time.Second},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := keycdntest.NewServer()
+			defer s.Close()
+			z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+			s.Throttle("/zones/1.json", tc.retryAfter, 1)
+			// 429 responses are retried even without WithRetry
+			c, clock := newRetryClient(t, s)
+
+			if _, err := c.Zone(context.Background(), z.ID); err != nil {
+				t.Fatal(err)
+			}
+			if want := []time.Duration{tc.want}; !reflect.DeepEqual(clock.Waits(), want) {
+				t.Errorf("waits = %v, want %v", clock.Waits(), want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterGivesUp(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	s.Throttle("/zones/1.json", "2", 10)
+	c, _ := newRetryClient(t, s, keycdn.WithRetry(2, time.Second))
+
+	if _, err := c.Zone(context.Background(), z.ID); !errors.Is(err, keycdn.ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	// throttled retries do not use up the attempts of WithRetry
+	if n := s.Requests("/zones/1.json"); n != 4 {
+		t.Errorf("%d requests sent, want 4", n)
+	}
+}
+
+func TestRetryAfterSkipsPost(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	s.Throttle("/zones.json", "1", 1)
+	c, _ := newRetryClient(t, s)
+
+	if _, err := c.AddZone(context.Background(), keycdn.ZoneCreateRequest{Name: "assets"}); !errors.Is(err, keycdn.ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if n := s.Requests("/zones.json"); n != 1 {
+		t.Errorf("%d requests sent, want 1", n)
+	}
+}
+
+func TestRetryAfterBoundedByContext(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	s.Throttle("/zones/1.json", "3600", 1)
+	// the clock does not advance, so only the context ends the wait
+	clock := keycdntest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL), keycdn.WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Zone(ctx, z.ID); !errors.Is(err, keycdn.ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if n := s.Requests("/zones/1.json"); n != 1 {
+		t.Errorf("%d requests sent, want 1", n)
+	}
+}
+
+func TestRetryJitter(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	s.Fail("/zones/1.json", http.StatusBadGateway, 20)
+	c, clock := newRetryClient(t, s, keycdn.WithRetry(21, time.Second), keycdn.WithRetryJitter(0.5))
+
+	if _, err := c.Zone(context.Background(), z.ID); err != nil {
+		t.Fatal(err)
+	}
+	waits := clock.Waits()
+	if len(waits) != 20 {
+		t.Fatalf("%d waits, want 20", len(waits))
+	}
+	shortened := false
+	for i, got := range waits {
+		full := 30 * time.Second
+		if i < 5 {
+			full = time.Second << i
+		}
+		if got < full/2 || got > full {
+			t.Errorf("wait %d = %s, want between %s and %s", i, got, full/2, full)
+		}
+		shortened = shortened || got < full
+	}
+	if !shortened {
+		t.Errorf("waits = %v, want them shortened randomly", waits)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	ctx := context.Background()
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	s.Fail("/zones/1.json", http.StatusBadGateway, 5)
+	c, clock := newRetryClient(t, s, keycdn.WithRetry(10, time.Second), keycdn.WithRetryBudget(2, time.Minute))
+
+	if _, err := c.Zone(ctx, z.ID); err == nil {
+		t.Fatal("Zone succeeded, want the retry budget to run out")
+	}
+	if n := s.Requests("/zones/1.json"); n != 3 {
+		t.Errorf("%d requests sent with a budget of 2 retries, want 3", n)
+	}
+
+	// without budget a failure is not retried
+	if _, err := c.Zone(ctx, z.ID); err == nil {
+		t.Fatal("Zone succeeded, want the retry budget to be exhausted")
+	}
+	if n := s.Requests("/zones/1.json"); n != 4 {
+		t.Errorf("%d requests sent, want 4", n)
+	}
+
+	// the budget refills over time
+	clock.Advance(time.Minute)
+	if _, err := c.Zone(ctx, z.ID); err != nil {
+		t.Fatalf("Zone after the budget refilled: %v", err)
+	}
+	if n := s.Requests("/zones/1.json"); n != 6 {
+		t.Errorf("%d requests sent, want 6", n)
+	}
+}
@@ -0,0 +1,46 @@
+package keycdn
+
+import (
+	"context"
+	"fmt"
+)
+
+// SecureTokenConfig is the secure token setup of a zone. With secure tokens
+// enabled, the zone only serves URLs signed with the key, see SignURL.
+type SecureTokenConfig struct {
+	Enabled bool
+	Key     string
+}
+
+// ZoneSecureToken returns the secure token setup of a zone
+func (c *Client) ZoneSecureToken(ctx context.Context, zoneID uint64) (SecureTokenConfig, error) {
+	z, err := c.Zone(ctx, zoneID)
+	if err != nil {
+		return SecureTokenConfig{}, err
+	}
+	return SecureTokenConfig{Enabled: z.SecureToken, Key: z.SecureTokenKey}, nil
+}
+
+// EnableSecureToken enables secure tokens for a zone. If key is empty the
+// current key is kept.
+func (c *Client) EnableSecureToken(ctx context.Context, zoneID uint64, key string) (Zone, error) {
+	u := NewZoneUpdate(zoneID).SetSecureToken(true)
+	if key != "" {
+		u.SetSecureTokenKey(key)
+	}
+	return c.UpdateZone(ctx, u)
+}
+
+// DisableSecureToken disables secure tokens for a zone. The key is kept.
+func (c *Client) DisableSecureToken(ctx context.Context, zoneID uint64) (Zone, error) {
+	return c.UpdateZone(ctx, NewZoneUpdate(zoneID).SetSecureToken(false))
+}
+
+// SetSecureTokenKey replaces the secure token key of a zone. URLs signed
+// with the old key stop working.
+func (c *Client) SetSecureTokenKey(ctx context.Context, zoneID uint64, key string) (Zone, error) {
+	if key == "" {
+		return Zone{}, fmt.Errorf("Failed to set secure token key of Zone %d: key is empty", zoneID)
+	}
+	return c.UpdateZone(ctx, NewZoneUpdate(zoneID).SetSecureTokenKey(key))
+}
@@ -0,0 +1,43 @@
+package keycdn
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SignURL returns rawURL, e.g. https://cdn.example.com/file.zip, signed with
+// the secure token key of a zone. The signed URL is valid until expires.
+//
+// The token is the unpadded, URL-safe base64 encoding of the MD5 sum of the
+// path, the key and the expiry timestamp. It is added to the query together
+// with the expiry timestamp.
+func SignURL(rawURL, key string, expires time.Time) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("Failed to sign URL %s: secure token key is empty", rawURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("Failed to sign URL %s: %w", rawURL, err)
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	expire := strconv.FormatInt(expires.Unix(), 10)
+	sum := md5.Sum([]byte(path + key + expire))
+
+	q := u.Query()
+	q.Set("token", base64.RawURLEncoding.EncodeToString(sum[:]))
+	q.Set("expire", expire)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// SignURL signs rawURL with the secure token key of the zone, see SignURL
+func (z Zone) SignURL(rawURL string, expires time.Time) (string, error) {
+	return SignURL(rawURL, z.SecureTokenKey, expires)
+}
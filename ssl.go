@@ -0,0 +1,156 @@
+package keycdn
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Values of Zone.SSLCert
+const (
+	SSLCertShared      = "shared"
+	SSLCertLetsEncrypt = "letsencrypt"
+	SSLCertCustom      = "custom"
+)
+
+// SSLStatus describes the certificate setup of a zone
+type SSLStatus struct {
+	// Type is one of the SSLCert constants
+	Type     string
+	ForceSSL bool
+	// Expires is the expiry date of a custom certificate. It is zero for
+	// shared and Let's Encrypt certificates which are renewed by KeyCDN.
+	Expires time.Time
+	// Checked is the time the status was fetched according to the clock of
+	// the client
+	Checked time.Time
+}
+
+// ExpiresWithin returns true if the certificate has a known expiry date
+// which was less than d after Checked
+func (s SSLStatus) ExpiresWithin(d time.Duration) bool {
+	if s.Expires.IsZero() {
+		return false
+	}
+	return s.Expires.Before(s.Checked.Add(d))
+}
+
+// ZoneSSLStatus returns the certificate setup of a zone. For custom
+// certificates the expiry date is taken from the certificate.
+func (c *Client) ZoneSSLStatus(ctx context.Context, zoneID uint64) (SSLStatus, error) {
+	z, err := c.Zone(ctx, zoneID)
+	if err != nil {
+		return SSLStatus{}, err
+	}
+	status := SSLStatus{
+		Type:     z.SSLCert,
+		ForceSSL: z.ForceSSL,
+		Checked:  c.clock.Now(),
+	}
+	if z.SSLCert != SSLCertCustom || *z.customSSLCert() == "" {
+		return status, nil
+	}
+	cert, err := parseCertificate(z.CustomSSLCert)
+	if err != nil {
+		return status, fmt.Errorf("Failed to parse certificate of Zone %d: %w", zoneID, err)
+	}
+	status.Expires = cert.NotAfter
+	return status, nil
+}
+
+// SetZoneCertificate uploads a custom certificate and its private key to a
+// zone and switches the zone to it. certPEM holds the PEM encoded
+// certificate followed by its intermediate certificates. The certificate is
+// checked before uploading: it must match the key, be currently valid and
+// cover all aliases of the zone. The chain must lead to a trusted root or
+// end with a self-signed certificate, otherwise the error matches
+// ErrIncompleteChain.
+func (c *Client) SetZoneCertificate(ctx context.Context, zoneID uint64, certPEM, keyPEM string) (Zone, error) {
+	aliases, err := c.ZoneAliases(ctx)
+	if err != nil {
+		return Zone{}, err
+	}
+	var hosts []string
+	for _, a := range aliases {
+		if a.ZoneID == zoneID {
+			hosts = append(hosts, a.Name)
+		}
+	}
+	if err := validateCertificate(certPEM, keyPEM, hosts, nil, c.clock.Now()); err != nil {
+		return Zone{}, fmt.Errorf("Invalid certificate for Zone %d: %w", zoneID, err)
+	}
+	vs := url.Values{}
+	vs.Set("sslcert", SSLCertCustom)
+	vs.Set("customsslcert", certPEM)
+	vs.Set("customsslkey", keyPEM)
+	return c.editZone(ctx, zoneID, vs)
+}
+
+// validateCertificate checks that the PEM encoded chain matches the key, is
+// valid for all hosts and leads to one of roots or a self-signed
+// certificate at now. The system roots are used if roots is nil.
+func validateCertificate(certPEM, keyPEM string, hosts []string, roots *x509.CertPool, now time.Time) error {
+	pair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return err
+	}
+	chain := make([]*x509.Certificate, 0, len(pair.Certificate))
+	for _, der := range pair.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return err
+		}
+		chain = append(chain, cert)
+	}
+
+	leaf := chain[0]
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return fmt.Errorf("certificate is only valid from %s to %s", leaf.NotBefore.Format(time.RFC3339), leaf.NotAfter.Format(time.RFC3339))
+	}
+	for i, cert := range chain[1:] {
+		if err := chain[i].CheckSignatureFrom(cert); err != nil {
+			return fmt.Errorf("certificate %q is not signed by the next certificate %q: %w", chain[i].Subject, cert.Subject, err)
+		}
+	}
+	for _, host := range hosts {
+		if err := leaf.VerifyHostname(host); err != nil {
+			return err
+		}
+	}
+
+	opts := x509.VerifyOptions{
+		Intermediates: x509.NewCertPool(),
+		Roots:         roots,
+		CurrentTime:   now,
+	}
+	for _, cert := range chain[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	// a self-signed certificate at the end of the chain is trusted as is
+	last := chain[len(chain)-1]
+	if bytes.Equal(last.RawIssuer, last.RawSubject) {
+		opts.Roots = x509.NewCertPool()
+		opts.Roots.AddCert(last)
+	}
+	_, err = leaf.Verify(opts)
+	var unknown x509.UnknownAuthorityError
+	if errors.As(err, &unknown) {
+		return fmt.Errorf("%w: no certificate for issuer %q, append the intermediate certificates after the certificate", ErrIncompleteChain, last.Issuer)
+	}
+	return err
+}
+
+// parseCertificate parses the first certificate of a PEM encoded chain
+func parseCertificate(data string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,94 @@
+package keycdn
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateCertificate(t *testing.T) {
+	now := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	from, to := now.Add(-24*time.Hour), now.Add(90*24*time.Hour)
+	root := newTestCert(t, "Test Root", from, to, nil, true)
+	intermediate := newTestCert(t, "Test Intermediate", from, to, root, true)
+	leaf := newTestCert(t, "cdn.example.com", from, to, intermediate, false)
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	expiredIntermediate := newTestCert(t, "Expired Intermediate", from.Add(-48*time.Hour), from, root, true)
+	leafOfExpired := newTestCert(t, "cdn.example.com", from, to, expiredIntermediate, false)
+	expiredLeaf := newTestCert(t, "cdn.example.com", from.Add(-48*time.Hour), from, intermediate, false)
+	selfSigned := newTestCert(t, "cdn.example.com", from, to, nil, false)
+	hosts := []string{"cdn.example.com"}
+
+	t.Run("chain to a trusted root", func(t *testing.T) {
+		if err := validateCertificate(leaf.certPEM+intermediate.certPEM, leaf.keyPEM, hosts, roots, now); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("chain ending with a self-signed root", func(t *testing.T) {
+		if err := validateCertificate(leaf.certPEM+intermediate.certPEM+root.certPEM, leaf.keyPEM, hosts, x509.NewCertPool(), now); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("self-signed", func(t *testing.T) {
+		if err := validateCertificate(selfSigned.certPEM, selfSigned.keyPEM, hosts, x509.NewCertPool(), now); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("incomplete chain", func(t *testing.T) {
+		err := validateCertificate(leaf.certPEM, leaf.keyPEM, hosts, roots, now)
+		if !errors.Is(err, ErrIncompleteChain) || !strings.Contains(err.Error(), "Test Intermediate") {
+			t.Errorf("err = %v, want ErrIncompleteChain naming the missing issuer", err)
+		}
+	})
+	t.Run("expired", func(t *testing.T) {
+		err := validateCertificate(expiredLeaf.certPEM+intermediate.certPEM, expiredLeaf.keyPEM, hosts, roots, now)
+		if err == nil || !strings.Contains(err.Error(), "only valid from") {
+			t.Errorf("err = %v, want an error about the validity period", err)
+		}
+	})
+	t.Run("expired intermediate", func(t *testing.T) {
+		err := validateCertificate(leafOfExpired.certPEM+expiredIntermediate.certPEM, leafOfExpired.keyPEM, hosts, roots, now)
+		var invalid x509.CertificateInvalidError
+		if !errors.As(err, &invalid) || invalid.Reason != x509.Expired || errors.Is(err, ErrIncompleteChain) {
+			t.Errorf("err = %v, want the expiry reported by the verification", err)
+		}
+	})
+	t.Run("wrong host", func(t *testing.T) {
+		err := validateCertificate(leaf.certPEM+intermediate.certPEM, leaf.keyPEM, []string{"cdn.example.com", "www.example.org"}, roots, now)
+		var hostErr x509.HostnameError
+		if !errors.As(err, &hostErr) || hostErr.Host != "www.example.org" {
+			t.Errorf("err = %v, want a hostname error for www.example.org", err)
+		}
+	})
+	t.Run("wrong key", func(t *testing.T) {
+		if err := validateCertificate(leaf.certPEM+intermediate.certPEM, selfSigned.keyPEM, hosts, roots, now); err == nil {
+			t.Error("a certificate was accepted with another key")
+		}
+	})
+}
+
+func TestSetZoneCertificateChecksAliases(t *testing.T) {
+	now := time.Now()
+	cert := newTestCert(t, "cdn.example.com", now.Add(-time.Hour), now.Add(time.Hour), nil, false)
+	c, ts := newTestServer(t, zoneBody)
+	ts.route(http.MethodGet, "/zonealiases.json", `{"status":"success","data":{"zonealiases":[
+		{"id":"1","zone_id":"1","name":"cdn.example.com"},
+		{"id":"2","zone_id":"1","name":"static.example.com"},
+		{"id":"3","zone_id":"2","name":"other.example.org"}
+	]}}`)
+
+	_, err := c.SetZoneCertificate(context.Background(), 1, cert.certPEM, cert.keyPEM)
+	var hostErr x509.HostnameError
+	if !errors.As(err, &hostErr) || hostErr.Host != "static.example.com" {
+		t.Errorf("err = %v, want a hostname error for static.example.com", err)
+	}
+	if n := ts.count(http.MethodPut); n != 0 {
+		t.Errorf("%d invalid certificates were uploaded", n)
+	}
+}
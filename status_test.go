@@ -0,0 +1,65 @@
+package keycdn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatusUnmarshal(t *testing.T) {
+	for in, want := range map[string]status{
+		`"success"`: "success",
+		`"error"`:   "error",
+		`200`:       "success",
+		`204`:       "success",
+		`404`:       "404",
+		`500`:       "500",
+	} {
+		var got status
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Errorf("%s: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("status %s = %q, want %q", in, got, want)
+		}
+	}
+	var s status
+	if err := json.Unmarshal([]byte(`true`), &s); err == nil {
+		t.Errorf("status true = %q, want an error", s)
+	}
+}
+
+func TestNumericStatusSuccess(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestServer(t, `{"status":200,"description":"Cache has been cleared","data":{"stats":[{"totalcachehit":"3"}]}}`)
+
+	stats, err := c.Stats(ctx, 1, time.Unix(1700000000, 0), time.Unix(1700003600, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats["totalcachehit"] != 3 {
+		t.Errorf("cache hits = %d, want 3", stats["totalcachehit"])
+	}
+	if err := c.PurgeZoneCache(ctx, 1); err != nil {
+		t.Errorf("PurgeZoneCache with status 200: %v", err)
+	}
+}
+
+func TestNumericStatusError(t *testing.T) {
+	c, _ := newTestServer(t, `{"status":404,"description":"Zone not found"}`)
+
+	_, err := c.Stats(context.Background(), 1, time.Unix(1700000000, 0), time.Unix(1700003600, 0))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.Status != "404" || apiErr.Description != "Zone not found" {
+		t.Errorf("err = %+v, want status 404 with the description", apiErr)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want it to match ErrNotFound", err)
+	}
+}
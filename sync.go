@@ -0,0 +1,212 @@
+package keycdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SyncConfig is the desired state of the zones of an account, e.g. kept in
+// a YAML or JSON file in version control:
+//
+//	zones:
+//	  - name: assets
+//	    type: pull
+//	    origin_url: https://example.com
+//	    gzip: true
+//	prune: false
+type SyncConfig struct {
+	Zones []ZoneConfig `json:"zones"`
+	// Prune deletes zones which are not listed in Zones
+	Prune bool `json:"prune,omitempty"`
+}
+
+// LoadSyncConfig reads a YAML or JSON encoded SyncConfig. Both use the
+// field names of the JSON encoding. Unknown fields are rejected to catch
+// typos.
+func LoadSyncConfig(r io.Reader) (SyncConfig, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return SyncConfig{}, fmt.Errorf("Failed to read sync config: %w", err)
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		if b, err = yamlToJSON(b); err != nil {
+			return SyncConfig{}, fmt.Errorf("Failed to read sync config: %w", err)
+		}
+	}
+	var cfg SyncConfig
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return SyncConfig{}, fmt.Errorf("Failed to read sync config: %w", err)
+	}
+	names := make(map[string]bool, len(cfg.Zones))
+	for i, zc := range cfg.Zones {
+		if zc.Name == "" {
+			return SyncConfig{}, fmt.Errorf("Failed to read sync config: zone %d has no name", i)
+		}
+		if names[zc.Name] {
+			return SyncConfig{}, fmt.Errorf("Failed to read sync config: zone %q is listed twice", zc.Name)
+		}
+		names[zc.Name] = true
+	}
+	return cfg, nil
+}
+
+// yamlToJSON converts a YAML document to JSON so it can be decoded like a
+// JSON config
+func yamlToJSON(b []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("empty document")
+	}
+	return json.Marshal(v)
+}
+
+// SyncAction is the kind of a SyncStep
+type SyncAction string
+
+// Sync actions
+const (
+	SyncCreate SyncAction = "create"
+	SyncUpdate SyncAction = "update"
+	SyncDelete SyncAction = "delete"
+)
+
+// SyncStep is a single change of a SyncPlan
+type SyncStep struct {
+	Action SyncAction
+	// Zone is the desired zone for creates and updates and the existing
+	// zone for deletes. Its ID is set unless the zone is created.
+	Zone Zone
+	// Config is the desired configuration for creates and updates
+	Config ZoneConfig
+	// Changes lists the changed settings of updates
+	Changes ZoneDiff
+}
+
+// SyncPlan is the list of changes which converge an account towards a
+// SyncConfig. An empty plan means there is no drift.
+type SyncPlan struct {
+	Steps []SyncStep
+}
+
+// PlanSync compares the zones of the account with the desired state and
+// returns the changes needed to converge. Nothing is changed. Zones are
+// matched by ID if the config has one and by name otherwise. Settings
+// are compared like by ZoneConfig.Diff, so settings left out of the config
+// are not changed.
+func PlanSync(ctx context.Context, api API, cfg SyncConfig) (*SyncPlan, error) {
+	zones, err := api.Zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]Zone, len(zones))
+	dups := make(map[string]bool)
+	for _, z := range zones {
+		if _, found := byName[z.Name]; found {
+			dups[z.Name] = true
+		}
+		byName[z.Name] = z
+	}
+
+	plan := &SyncPlan{}
+	matched := make(map[uint64]bool, len(cfg.Zones))
+	for _, zc := range cfg.Zones {
+		desired := zc.Zone()
+		actual, found := zones[zc.ID]
+		if zc.ID == 0 {
+			if dups[zc.Name] {
+				return nil, fmt.Errorf("Failed to plan sync: multiple zones named %q, set the id in the config", zc.Name)
+			}
+			actual, found = byName[zc.Name]
+		}
+		if !found {
+			if zc.ID != 0 {
+				return nil, fmt.Errorf("Failed to plan sync of Zone %d: %w", zc.ID, ErrZoneNotFound)
+			}
+			plan.Steps = append(plan.Steps, SyncStep{Action: SyncCreate, Zone: desired, Config: zc})
+			continue
+		}
+		matched[actual.ID] = true
+		if d := zc.Diff(actual); len(d) > 0 {
+			desired.ID = actual.ID
+			plan.Steps = append(plan.Steps, SyncStep{Action: SyncUpdate, Zone: desired, Config: zc, Changes: d})
+		}
+	}
+
+	if cfg.Prune {
+		var deletes []SyncStep
+		for id, z := range zones {
+			if !matched[id] {
+				deletes = append(deletes, SyncStep{Action: SyncDelete, Zone: z})
+			}
+		}
+		sort.Slice(deletes, func(i, j int) bool {
+			return deletes[i].Zone.ID < deletes[j].Zone.ID
+		})
+		plan.Steps = append(plan.Steps, deletes...)
+	}
+	return plan, nil
+}
+
+// Empty returns true if the plan has no changes
+func (p *SyncPlan) Empty() bool {
+	return len(p.Steps) == 0
+}
+
+// String formats the plan for review, one zone per line followed by the
+// changed settings of updates
+func (p *SyncPlan) String() string {
+	if p.Empty() {
+		return "no changes\n"
+	}
+	var sb strings.Builder
+	for _, s := range p.Steps {
+		switch s.Action {
+		case SyncCreate:
+			fmt.Fprintf(&sb, "+ create zone %s\n", s.Zone.Name)
+		case SyncUpdate:
+			fmt.Fprintf(&sb, "~ update zone %s (%d)\n", s.Zone.Name, s.Zone.ID)
+			for _, line := range strings.SplitAfter(s.Changes.String(), "\n") {
+				if line != "" {
+					sb.WriteString("    " + line)
+				}
+			}
+		case SyncDelete:
+			fmt.Fprintf(&sb, "- delete zone %s (%d)\n", s.Zone.Name, s.Zone.ID)
+		}
+	}
+	return sb.String()
+}
+
+// Apply executes the steps of the plan in order. It stops at the first
+// failing step.
+func (p *SyncPlan) Apply(ctx context.Context, api API) error {
+	for _, s := range p.Steps {
+		var err error
+		switch s.Action {
+		case SyncCreate:
+			_, err = api.AddZone(ctx, s.Config.CreateRequest())
+		case SyncUpdate:
+			_, err = api.UpdateZone(ctx, s.Changes.Update(s.Zone.ID))
+		case SyncDelete:
+			err = api.DeleteZone(ctx, s.Zone.ID)
+		default:
+			err = fmt.Errorf("unknown action %q", s.Action)
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to %s zone %s: %w", s.Action, s.Zone.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package keycdn_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+func TestPlanSyncKeepsOmittedSettings(t *testing.T) {
+	ctx := context.Background()
+	f := keycdntest.NewFake()
+	z := f.SeedZone(keycdn.Zone{
+		Name:      "assets",
+		Type:      keycdn.ZoneTypePull,
+		OriginURL: "https://example.com",
+		CORS:      true,
+		HTTP2:     true,
+		ForceSSL:  true,
+	})
+
+	cfg, err := keycdn.LoadSyncConfig(strings.NewReader(`{
+	  "zones": [
+	    {"name": "assets", "type": "pull", "origin_url": "https://example.com", "gzip": true}
+	  ]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := keycdn.PlanSync(ctx, f, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Steps) != 1 || len(plan.Steps[0].Changes) != 1 || plan.Steps[0].Changes[0].Param != "gzip" {
+		t.Fatalf("plan = %s, want only gzip enabled", plan)
+	}
+	if err := plan.Apply(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.Zone(ctx, z.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Gzip || !got.CORS || !got.HTTP2 || !got.ForceSSL {
+		t.Errorf("zone after sync = %+v, want gzip, cors, http2 and forcessl enabled", got)
+	}
+}
+
+func TestPlanSyncDisablesExplicitFalse(t *testing.T) {
+	ctx := context.Background()
+	f := keycdntest.NewFake()
+	z := f.SeedZone(keycdn.Zone{Name: "assets", Type: keycdn.ZoneTypePull, CORS: true, HTTP2: true})
+
+	cfg := keycdn.SyncConfig{Zones: []keycdn.ZoneConfig{
+		{Name: "assets", CORS: keycdn.Bool(false)},
+	}}
+	plan, err := keycdn.PlanSync(ctx, f, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.Apply(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.Zone(ctx, z.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CORS || !got.HTTP2 {
+		t.Errorf("zone after sync = %+v, want cors disabled and http2 enabled", got)
+	}
+}
+
+func TestPlanSyncCreate(t *testing.T) {
+	ctx := context.Background()
+	f := keycdntest.NewFake()
+
+	cfg := keycdn.SyncConfig{Zones: []keycdn.ZoneConfig{
+		{Name: "assets", Type: keycdn.ZoneTypePull, OriginURL: "https://example.com", Gzip: keycdn.Bool(true)},
+	}}
+	plan, err := keycdn.PlanSync(ctx, f, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Steps) != 1 || plan.Steps[0].Action != keycdn.SyncCreate {
+		t.Fatalf("plan = %s, want one create", plan)
+	}
+	if err := plan.Apply(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.ZoneByName(ctx, "assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Gzip || got.OriginURL != "https://example.com" {
+		t.Errorf("created zone = %+v", got)
+	}
+}
+
+func TestLoadSyncConfigYAML(t *testing.T) {
+	fromYAML, err := keycdn.LoadSyncConfig(strings.NewReader(`
+# zones served from the main origin
+zones:
+  - name: assets
+    type: pull
+    origin_url: https://example.com
+    gzip: true
+    cors: false
+    expire: 60
+  - name: images
+    type: pull
+prune: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := keycdn.LoadSyncConfig(strings.NewReader(`{
+	  "zones": [
+	    {"name": "assets", "type": "pull", "origin_url": "https://example.com", "gzip": true, "cors": false, "expire": 60},
+	    {"name": "images", "type": "pull"}
+	  ],
+	  "prune": true
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("YAML config = %+v, want it to match the JSON config %+v", fromYAML, fromJSON)
+	}
+	if cors := fromYAML.Zones[0].CORS; cors == nil || *cors {
+		t.Errorf("cors = %v, want it explicitly disabled", cors)
+	}
+	if fromYAML.Zones[1].Gzip != nil {
+		t.Errorf("gzip of images = %v, want it unset", *fromYAML.Zones[1].Gzip)
+	}
+}
+
+func TestLoadSyncConfigYAMLErrors(t *testing.T) {
+	for name, config := range map[string]string{
+		"unknown field":  "zones:\n  - name: assets\n    gzipp: true\n",
+		"syntax error":   "zones:\n  - name: [assets\n",
+		"empty document": "# nothing here\n",
+		"missing name":   "zones:\n  - type: pull\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := keycdn.LoadSyncConfig(strings.NewReader(config)); err == nil {
+				t.Errorf("LoadSyncConfig(%q) succeeded", config)
+			}
+		})
+	}
+}
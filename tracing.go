@@ -0,0 +1,83 @@
+package keycdn
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Tracer starts a span for every API call. It mirrors the small subset of
+// the OpenTelemetry tracing API used by the client, so an OpenTelemetry
+// tracer can be plugged in with a thin adapter without this package
+// depending on it.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced API call
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// WithTracer traces every API call, including all retries, as one span.
+// Spans carry the HTTP method, endpoint, zone ID, status code and number of
+// attempts as attributes.
+func WithTracer(t Tracer) Option {
+	return func(c *Client) {
+		c.tracer = t
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
+func (c *Client) startSpan(ctx context.Context, method, file, u string) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := c.tracer.Start(ctx, "keycdn "+method)
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("keycdn.endpoint", file)
+	if id := zoneIDOf(u); id != "" {
+		span.SetAttribute("keycdn.zone_id", id)
+	}
+	return ctx, span
+}
+
+func endSpan(span Span, statusCode, attempts int, err error) {
+	if statusCode != 0 {
+		span.SetAttribute("http.status_code", statusCode)
+	}
+	span.SetAttribute("keycdn.attempts", attempts)
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// zoneIDOf extracts the zone ID from a request URL, either from the zone_id
+// query parameter or from paths like /zones/purge/123.json
+func zoneIDOf(u string) string {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	if id := pu.Query().Get("zone_id"); id != "" {
+		return id
+	}
+	if !strings.HasPrefix(pu.Path, "/zones/") {
+		return ""
+	}
+	id := strings.TrimSuffix(path.Base(pu.Path), ".json")
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,156 @@
+package keycdn
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxExpire is the longest cache expiry in minutes the API accepts, one
+// year
+const maxExpire = 525600
+
+// zoneNameRE matches valid zone names
+var zoneNameRE = regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+
+// Violation is a setting rejected by Validate
+type Violation struct {
+	// Param is the API parameter, e.g. "originurl"
+	Param   string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Param + ": " + v.Message
+}
+
+// ValidationError lists all violations found by Validate
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.String())
+	}
+	return "invalid zone settings: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the settings of the request before it is sent. All
+// violations are returned at once as *ValidationError.
+func (r ZoneCreateRequest) Validate() error {
+	return validateNewZone(r.values())
+}
+
+// Validate checks the settings of the update before it is sent. Only the
+// settings set in the update are checked. All violations are returned at
+// once as *ValidationError.
+func (u *ZoneUpdate) Validate() error {
+	return validationError(validateZoneValues(u.values))
+}
+
+// validateNewZone checks the form parameters of a zone to be created
+func validateNewZone(vs url.Values) error {
+	if vs.Get("name") == "" {
+		required := Violation{Param: "name", Message: "is required"}
+		return validationError(append([]Violation{required}, validateZoneValues(vs)...))
+	}
+	return validationError(validateZoneValues(vs))
+}
+
+func validationError(vs []Violation) error {
+	if len(vs) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: vs}
+}
+
+// validateZoneValues checks the form parameters of a zone
+func validateZoneValues(vs url.Values) []Violation {
+	var violations []Violation
+	add := func(param, format string, args ...interface{}) {
+		violations = append(violations, Violation{Param: param, Message: fmt.Sprintf(format, args...)})
+	}
+	set := func(param string) bool {
+		_, found := vs[param]
+		return found
+	}
+
+	if set("name") && !zoneNameRE.MatchString(vs.Get("name")) {
+		add("name", "must be 1 to 20 lowercase letters or digits, got %q", vs.Get("name"))
+	}
+	if set("type") {
+		if _, err := ParseZoneType(vs.Get("type")); err != nil {
+			add("type", "%s", err)
+		}
+	}
+	if set("status") {
+		if _, err := ParseZoneStatus(vs.Get("status")); err != nil {
+			add("status", "%s", err)
+		}
+	}
+	if v := vs.Get("originurl"); v != "" {
+		u, err := url.Parse(v)
+		switch {
+		case err != nil:
+			add("originurl", "%s", err)
+		case u.Scheme != "http" && u.Scheme != "https":
+			add("originurl", "must be an http or https URL, got %q", v)
+		case u.Host == "":
+			add("originurl", "has no host: %q", v)
+		}
+	}
+	if vs.Get("type") == string(ZoneTypePush) {
+		for _, param := range []string{"originurl", "originhostheader", "cachepullkey"} {
+			if vs.Get(param) != "" {
+				add(param, "is not supported by push zones")
+			}
+		}
+		if vs.Get("originshield") == "enabled" {
+			add("originshield", "is not supported by push zones")
+		}
+	}
+
+	// expire -1 disables caching, cachemaxexpire 0 disables the limit
+	expire := validateExpire(vs, "expire", -1, add)
+	maxExp := validateExpire(vs, "cachemaxexpire", 0, add)
+	if expire > 0 && maxExp > 0 && expire > maxExp {
+		add("expire", "%d minutes exceeds cachemaxexpire of %d minutes", expire, maxExp)
+	}
+
+	switch sslCert := vs.Get("sslcert"); sslCert {
+	case "", SSLCertCustom:
+	case SSLCertShared, SSLCertLetsEncrypt:
+		for _, param := range []string{"customsslcert", "customsslkey"} {
+			if vs.Get(param) != "" {
+				add(param, "requires sslcert %q", SSLCertCustom)
+			}
+		}
+	default:
+		add("sslcert", "must be one of %s, %s or %s, got %q", SSLCertShared, SSLCertLetsEncrypt, SSLCertCustom, sslCert)
+	}
+	if vs.Get("webp") == "enabled" && vs.Get("imageprocessing") == "disabled" {
+		add("webp", "requires imageprocessing")
+	}
+	if vs.Get("securetoken") == "enabled" && set("securetokenkey") && vs.Get("securetokenkey") == "" {
+		add("securetokenkey", "must not be empty if securetoken is enabled")
+	}
+	return violations
+}
+
+// validateExpire checks an expiry in minutes and returns it, or 0 if it is
+// not set or invalid
+func validateExpire(vs url.Values, param string, min int, add func(param, format string, args ...interface{})) int {
+	if _, found := vs[param]; !found {
+		return 0
+	}
+	n, err := strconv.Atoi(vs.Get(param))
+	if err != nil || n < min || n > maxExpire {
+		add(param, "must be between %d and %d minutes, got %q", min, maxExpire, vs.Get(param))
+		return 0
+	}
+	return n
+}
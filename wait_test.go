@@ -0,0 +1,120 @@
+package keycdn_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominikschulz/keycdn/v2"
+	"github.com/dominikschulz/keycdn/v2/keycdntest"
+)
+
+// newWaitClient returns a client of s whose clock advances on every wait
+func newWaitClient(t *testing.T, s *keycdntest.Server) (*keycdn.Client, *keycdntest.Clock) {
+	t.Helper()
+	clock := keycdntest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.AutoAdvance(true)
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL), keycdn.WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, clock
+}
+
+func TestWaitForZoneActive(t *testing.T) {
+	ctx := context.Background()
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets", Status: "deploying"})
+	c, clock := newWaitClient(t, s)
+
+	var seen []keycdn.ZoneStatus
+	err := c.WaitForZoneActiveFunc(ctx, z.ID, 10*time.Second, func(status keycdn.ZoneStatus) {
+		seen = append(seen, status)
+		if len(seen) == 3 {
+			s.Fake.SeedZone(keycdn.Zone{ID: z.ID, Name: "assets", Status: keycdn.ZoneStatusActive})
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []keycdn.ZoneStatus{"deploying", "deploying", "deploying", keycdn.ZoneStatusActive}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("statuses = %v, want %v", seen, want)
+	}
+	// the interval doubles as long as it stays below 30 seconds
+	if want := []time.Duration{10 * time.Second, 20 * time.Second, 20 * time.Second}; !reflect.DeepEqual(clock.Waits(), want) {
+		t.Errorf("waits = %v, want %v", clock.Waits(), want)
+	}
+}
+
+func TestWaitForZoneActiveTerminalStatus(t *testing.T) {
+	for _, status := range []keycdn.ZoneStatus{keycdn.ZoneStatusInactive, keycdn.ZoneStatusPaused} {
+		t.Run(string(status), func(t *testing.T) {
+			s := keycdntest.NewServer()
+			defer s.Close()
+			z := s.Fake.SeedZone(keycdn.Zone{Name: "assets", Status: status})
+			c, clock := newWaitClient(t, s)
+
+			err := c.WaitForZoneActive(context.Background(), z.ID, time.Second)
+			if err == nil || !strings.Contains(err.Error(), "is "+string(status)) {
+				t.Errorf("err = %v, want an error naming the status", err)
+			}
+			if len(clock.Waits()) != 0 {
+				t.Errorf("waited %v for a zone which will not become active", clock.Waits())
+			}
+		})
+	}
+}
+
+func TestWaitForZoneActiveRetriesNotFound(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets"})
+	s.Fail("/zones/1.json", http.StatusNotFound, 2)
+	c, clock := newWaitClient(t, s)
+
+	if err := c.WaitForZoneActive(context.Background(), z.ID, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(clock.Waits(), want) {
+		t.Errorf("waits = %v, want %v", clock.Waits(), want)
+	}
+}
+
+func TestWaitForZoneActiveNotFoundGrace(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	c, clock := newWaitClient(t, s)
+	start := clock.Now()
+
+	err := c.WaitForZoneActive(context.Background(), 42, time.Second)
+	if !errors.Is(err, keycdn.ErrZoneNotFound) {
+		t.Fatalf("err = %v, want ErrZoneNotFound", err)
+	}
+	// 1+2+4+8+16 seconds pass the 30 second grace period
+	if waited := clock.Now().Sub(start); waited != 31*time.Second {
+		t.Errorf("gave up after %s, want 31s", waited)
+	}
+}
+
+func TestWaitForZoneActiveContext(t *testing.T) {
+	s := keycdntest.NewServer()
+	defer s.Close()
+	z := s.Fake.SeedZone(keycdn.Zone{Name: "assets", Status: "deploying"})
+	// the clock does not advance, so only the context ends the wait
+	clock := keycdntest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := keycdn.New("key", keycdn.WithBaseURL(s.URL), keycdn.WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = c.WaitForZoneActiveFunc(ctx, z.ID, time.Second, func(keycdn.ZoneStatus) { cancel() })
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), `"deploying"`) {
+		t.Errorf("err = %v, want context.Canceled with the last status", err)
+	}
+}
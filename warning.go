@@ -0,0 +1,51 @@
+package keycdn
+
+import "strings"
+
+// WithWarningHandler makes the client call fn with the endpoint and the
+// description of successful responses that carry a warning, e.g. when some
+// of the URLs of a purge request were invalid. Routine confirmations like
+// "Cache has been cleared" are not passed on.
+func WithWarningHandler(fn func(file, description string)) Option {
+	return func(c *Client) {
+		c.onWarning = fn
+	}
+}
+
+// confirmations are the complete descriptions KeyCDN uses to confirm
+// successful operations, in lower case and without the final period.
+// Anything else, e.g. a confirmation followed by a list of rejected URLs,
+// is a warning.
+var confirmations = map[string]bool{
+	"success":                            true,
+	"cache has been cleared":             true,
+	"cache cleared":                      true,
+	"zone has been created":              true,
+	"zone has been successfully created": true,
+	"zone has been updated":              true,
+	"zone has been successfully updated": true,
+	"zone deleted":                       true,
+	"zone has been deleted":              true,
+	"zone alias deleted":                 true,
+	"zone alias has been created":        true,
+	"zone referrer deleted":              true,
+	"zone referrer has been created":     true,
+	"edge rule deleted":                  true,
+	"edge rule has been created":         true,
+}
+
+// isWarning returns true if the description of a successful response is
+// more than a routine confirmation
+func isWarning(description string) bool {
+	desc := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(description)), ".")
+	return desc != "" && !confirmations[desc]
+}
+
+// warn passes the description of a successful response to the warning
+// handler if it is a warning
+func (c *Client) warn(file, description string) {
+	if c.onWarning == nil || !isWarning(description) {
+		return
+	}
+	c.onWarning(file, description)
+}
@@ -0,0 +1,57 @@
+package keycdn
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIsWarning(t *testing.T) {
+	for description, want := range map[string]bool{
+		"":                                   false,
+		"Cache has been cleared":             false,
+		"Cache has been cleared.":            false,
+		"  zone DELETED ":                    false,
+		"Zone has been successfully updated": false,
+		"Successfully purged 3 URLs, 2 URLs are invalid":        true,
+		"Cache has been cleared, 2 URLs were not purged":        true,
+		"Zone has been successfully created, SSL setup pending": true,
+		"Zone updated but the origin did not respond":           true,
+		"2 of 5 URLs are invalid and were skipped":              true,
+	} {
+		if got := isWarning(description); got != want {
+			t.Errorf("isWarning(%q) = %t, want %t", description, got, want)
+		}
+	}
+}
+
+func TestWarningHandlerReceivesPartialSuccess(t *testing.T) {
+	type warning struct{ file, description string }
+	var got []warning
+	c, _ := newTestServer(t, `{"status":"success","description":"Successfully purged 3 URLs, 2 URLs are invalid"}`,
+		WithWarningHandler(func(file, description string) {
+			got = append(got, warning{file, description})
+		}))
+
+	if err := c.PurgeZoneTag(context.Background(), 1, []string{"css"}); err != nil {
+		t.Fatal(err)
+	}
+	want := warning{"/zones/purgetag/1.json", "Successfully purged 3 URLs, 2 URLs are invalid"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("warnings = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestWarningHandlerIgnoresConfirmations(t *testing.T) {
+	called := false
+	c, _ := newTestServer(t, `{"status":"success","description":"Cache has been cleared"}`,
+		WithWarningHandler(func(file, description string) {
+			called = true
+		}))
+
+	if err := c.PurgeZoneCache(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Error("the warning handler was called for a routine confirmation")
+	}
+}
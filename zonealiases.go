@@ -0,0 +1,83 @@
+package keycdn
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ZoneAlias is a custom domain, e.g. cdn.example.com, which is served by a
+// zone. The domain needs a CNAME record pointing to the zone URL.
+type ZoneAlias struct {
+	ID     uint64
+	ZoneID uint64
+	Name   string
+}
+
+type zoneAliasResp map[string]string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *zoneAliasResp) UnmarshalJSON(b []byte) error {
+	m, err := unmarshalFlexMap(b)
+	*r = m
+	return err
+}
+
+// ToZoneAlias converts a zone alias response to a proper ZoneAlias object
+func (r zoneAliasResp) ToZoneAlias() ZoneAlias {
+	alias := ZoneAlias{
+		Name: r["name"],
+	}
+	if id, err := strconv.ParseUint(r["id"], 10, 64); err == nil {
+		alias.ID = id
+	}
+	if id, err := strconv.ParseUint(r["zone_id"], 10, 64); err == nil {
+		alias.ZoneID = id
+	}
+	return alias
+}
+
+// ZoneAliases returns the aliases of all zones
+func (c *Client) ZoneAliases(ctx context.Context) ([]ZoneAlias, error) {
+	var aliases []ZoneAlias
+	it := listIterator(ctx, c, "/zonealiases.json", nil, "zonealiases", zoneAliasResp.ToZoneAlias)
+	for it.Next() {
+		aliases = append(aliases, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to list zone aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// AddZoneAlias adds the domain name as alias to a zone and returns the new
+// alias
+func (c *Client) AddZoneAlias(ctx context.Context, zoneID uint64, name string) (ZoneAlias, error) {
+	vs := url.Values{}
+	vs.Set("zone_id", strconv.FormatUint(zoneID, 10))
+	vs.Set("name", name)
+	b, err := c.post(ctx, "/zonealiases.json", vs, encodingForm)
+	if err != nil {
+		return ZoneAlias{}, err
+	}
+	if c.dryRun {
+		return ZoneAlias{ZoneID: zoneID, Name: name}, nil
+	}
+	op := fmt.Sprintf("Failed to add alias %q to Zone %d", name, zoneID)
+	r, err := decodeData[zoneAliasResp](c, "/zonealiases.json", b, "zonealias", op)
+	if err != nil {
+		return ZoneAlias{}, err
+	}
+	return r.ToZoneAlias(), nil
+}
+
+// DeleteZoneAlias removes a zone alias
+func (c *Client) DeleteZoneAlias(ctx context.Context, id uint64) error {
+	file := "/zonealiases/" + strconv.FormatUint(id, 10) + ".json"
+	b, err := c.delete(ctx, file, nil)
+	if err != nil {
+		return err
+	}
+	return c.checkResponse(file, b, fmt.Sprintf("Failed to delete zone alias %d", id))
+}
@@ -0,0 +1,175 @@
+package keycdn
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ZoneCreateRequest holds the settings of a new zone. Only Name is required,
+// settings left nil or empty use the API defaults, so settings which are
+// enabled by default can be disabled explicitly.
+type ZoneCreateRequest struct {
+	Name string
+	// Type defaults to ZoneTypePull
+	Type             ZoneType
+	OriginURL        string
+	OriginHostHeader string
+	CachePullKey     string
+	SecureTokenKey   string
+	// SSLCert is one of the SSLCert constants. CustomSSLCert and
+	// CustomSSLKey are required for SSLCertCustom.
+	SSLCert        string
+	CustomSSLCert  string
+	CustomSSLKey   string
+	Expire         *int
+	CacheMaxExpire *int
+	ForceDownload  *bool
+	CORS           *bool
+	Gzip           *bool
+	HTTP2          *bool
+	ForceSSL       *bool
+	SecureToken    *bool
+	OriginShield   *bool
+	// cache settings
+	CacheIgnoreCacheControl *bool
+	CacheIgnoreQueryString  *bool
+	CacheStripCookies       *bool
+	CacheCanonical          *bool
+	CacheRobots             *bool
+	CacheHostHeader         *bool
+	// image processing settings
+	ImageProcessing *bool
+	WebP            *bool
+}
+
+// Bool returns a pointer to b, for use in ZoneCreateRequest
+func Bool(b bool) *bool {
+	return &b
+}
+
+// Int returns a pointer to i, for use in ZoneCreateRequest
+func Int(i int) *int {
+	return &i
+}
+
+// values encodes the settings which are set as form parameters
+func (r ZoneCreateRequest) values() url.Values {
+	vs := url.Values{}
+	for param, v := range map[string]string{
+		"name":             r.Name,
+		"type":             string(r.Type),
+		"originurl":        r.OriginURL,
+		"originhostheader": r.OriginHostHeader,
+		"cachepullkey":     r.CachePullKey,
+		"securetokenkey":   r.SecureTokenKey,
+		"sslcert":          r.SSLCert,
+		"customsslcert":    r.CustomSSLCert,
+		"customsslkey":     r.CustomSSLKey,
+	} {
+		if v != "" {
+			vs.Set(param, v)
+		}
+	}
+	for param, v := range map[string]*int{
+		"expire":         r.Expire,
+		"cachemaxexpire": r.CacheMaxExpire,
+	} {
+		if v != nil {
+			vs.Set(param, strconv.Itoa(*v))
+		}
+	}
+	for param, v := range map[string]*bool{
+		"forcedownload":           r.ForceDownload,
+		"cors":                    r.CORS,
+		"gzip":                    r.Gzip,
+		"http2":                   r.HTTP2,
+		"forcessl":                r.ForceSSL,
+		"securetoken":             r.SecureToken,
+		"originshield":            r.OriginShield,
+		"cacheignorecachecontrol": r.CacheIgnoreCacheControl,
+		"cacheignorequerystring":  r.CacheIgnoreQueryString,
+		"cachestripcookies":       r.CacheStripCookies,
+		"cachecanonical":          r.CacheCanonical,
+		"cacherobots":             r.CacheRobots,
+		"cachehostheader":         r.CacheHostHeader,
+		"imageprocessing":         r.ImageProcessing,
+		"webp":                    r.WebP,
+	} {
+		if v == nil {
+			continue
+		}
+		if *v {
+			vs.Set(param, "enabled")
+		} else {
+			vs.Set(param, "disabled")
+		}
+	}
+	return vs
+}
+
+// Zone returns the zone described by the request. Unset settings are zero.
+func (r ZoneCreateRequest) Zone() Zone {
+	zr := zoneResp{}
+	vs := r.values()
+	for k := range vs {
+		zr[k] = vs.Get(k)
+	}
+	return zr.ToZone()
+}
+
+// CreateRequest returns a request which creates a zone with the settings of
+// z. All boolean settings are set explicitly, so the new zone matches z
+// regardless of the API defaults. The ID and status are not copied.
+func (z Zone) CreateRequest() ZoneCreateRequest {
+	return ZoneCreateRequest{
+		Name:                    z.Name,
+		Type:                    z.Type,
+		OriginURL:               z.OriginURL,
+		OriginHostHeader:        z.OriginHostHeader,
+		CachePullKey:            z.CachePullKey,
+		SecureTokenKey:          z.SecureTokenKey,
+		SSLCert:                 z.SSLCert,
+		CustomSSLCert:           *z.customSSLCert(),
+		CustomSSLKey:            z.CustomSSLKey,
+		Expire:                  Int(z.Expire),
+		CacheMaxExpire:          Int(z.CacheMaxExpire),
+		ForceDownload:           Bool(z.ForceDownload),
+		CORS:                    Bool(z.CORS),
+		Gzip:                    Bool(z.Gzip),
+		HTTP2:                   Bool(z.HTTP2),
+		ForceSSL:                Bool(z.ForceSSL),
+		SecureToken:             Bool(z.SecureToken),
+		OriginShield:            Bool(z.OriginShield),
+		CacheIgnoreCacheControl: Bool(z.CacheIgnoreCacheControl),
+		CacheIgnoreQueryString:  Bool(z.CacheIgnoreQueryString),
+		CacheStripCookies:       Bool(z.CacheStripCookies),
+		CacheCanonical:          Bool(z.CacheCanonical),
+		CacheRobots:             Bool(z.CacheRobots),
+		CacheHostHeader:         Bool(z.CacheHostHeader),
+		ImageProcessing:         Bool(z.ImageProcessing),
+		WebP:                    Bool(z.WebP),
+	}
+}
+
+// AddZone creates a new zone and returns it as reported by the API. The
+// request is validated first, see ZoneCreateRequest.Validate.
+func (c *Client) AddZone(ctx context.Context, r ZoneCreateRequest) (Zone, error) {
+	return c.createZone(ctx, r.Name, r.values())
+}
+
+// createZone validates and sends the form parameters of a new zone
+func (c *Client) createZone(ctx context.Context, name string, vs url.Values) (Zone, error) {
+	if err := validateNewZone(vs); err != nil {
+		return Zone{}, fmt.Errorf("Failed to create Zone %s: %w", name, err)
+	}
+	b, err := c.post(ctx, "/zones.json", vs, encodingForm)
+	if err != nil {
+		return Zone{}, err
+	}
+	if c.dryRun {
+		return dryRunZone(0, vs), nil
+	}
+	return c.decodeZone("/zones.json", b, fmt.Sprintf("create Zone %s", name))
+}
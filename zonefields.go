@@ -0,0 +1,112 @@
+package keycdn
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/dominikschulz/keycdn/v2/internal/zonewire"
+)
+
+// zoneField is a settable field of the Zone struct, see zonewire.Fields
+type zoneField struct {
+	zonewire.Field
+	index int
+}
+
+// zoneFields lists all settable zone fields
+var zoneFields = func() []zoneField {
+	t := reflect.TypeOf(Zone{})
+	fields := make([]zoneField, 0, len(zonewire.Fields))
+	for _, wf := range zonewire.Fields {
+		sf, found := t.FieldByName(wf.Name)
+		if !found {
+			panic("keycdn: unknown zone field " + wf.Name)
+		}
+		fields = append(fields, zoneField{Field: wf, index: sf.Index[0]})
+	}
+	return fields
+}()
+
+// value returns the field of z
+func (f zoneField) value(z *Zone) reflect.Value {
+	if f.Param == "customsslcert" {
+		// fall back to the deprecated field
+		z.customSSLCert()
+	}
+	return reflect.ValueOf(z).Elem().Field(f.index)
+}
+
+// format returns the wire representation of the field. Booleans are sent as
+// "enabled" or "disabled".
+func (f zoneField) format(z *Zone) string {
+	v := f.value(z)
+	switch f.Kind {
+	case zonewire.Flag:
+		if v.Bool() {
+			return zonewire.Enabled
+		}
+		return zonewire.Disabled
+	case zonewire.Number:
+		return strconv.FormatInt(v.Int(), 10)
+	}
+	return v.String()
+}
+
+// parse sets the field from its wire representation. Invalid numbers are
+// ignored.
+func (f zoneField) parse(z *Zone, s string) {
+	v := f.value(z)
+	switch f.Kind {
+	case zonewire.Flag:
+		v.SetBool(parseFlag(s))
+	case zonewire.Number:
+		if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+			v.SetInt(int64(n))
+		}
+	default:
+		v.SetString(s)
+	}
+}
+
+// parseFlag parses a boolean setting. The API uses "enabled" and
+// "disabled", some responses carry booleans or numbers instead.
+func parseFlag(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "enabled", "true", "1", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// isZero returns true if the field is unset in z. A Zone can't tell an unset
+// boolean from false, so disabled settings count as unset and are never
+// sent from a Zone. ZoneUpdate and ZoneCreateRequest can disable settings.
+func (f zoneField) isZero(z *Zone) bool {
+	v := f.value(z)
+	switch f.Kind {
+	case zonewire.Flag:
+		return !v.Bool()
+	case zonewire.Number:
+		return v.Int() == 0
+	}
+	return v.String() == ""
+}
+
+// zoneValues encodes all set fields of the zone as form parameters
+func zoneValues(z Zone) url.Values {
+	vs := url.Values{}
+	for _, f := range zoneFields {
+		if f.isZero(&z) {
+			continue
+		}
+		vs.Set(f.Param, f.format(&z))
+	}
+	return vs
+}
+
+// zoneChanges encodes all non-zero fields of desired that differ from actual
+func zoneChanges(desired, actual Zone) url.Values {
+	return Diff(desired, actual).values()
+}
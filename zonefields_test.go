@@ -0,0 +1,169 @@
+package keycdn
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/dominikschulz/keycdn/v2/internal/zonewire"
+)
+
+// fullZone returns a zone with every mapped field set to a distinct value
+func fullZone() Zone {
+	z := Zone{ID: 7}
+	for i, f := range zoneFields {
+		v := reflect.ValueOf(&z).Elem().Field(f.index)
+		switch f.Kind {
+		case zonewire.Flag:
+			v.SetBool(true)
+		case zonewire.Number:
+			v.SetInt(int64(i + 1))
+		default:
+			v.SetString("value-" + f.Param)
+		}
+	}
+	z.CunstomSSLCert = z.CustomSSLCert
+	return z
+}
+
+func TestZoneFieldsRoundTrip(t *testing.T) {
+	z := fullZone()
+	vs := zoneValues(z)
+	if len(vs) != len(zoneFields) {
+		t.Errorf("zoneValues encoded %d of %d fields: %v", len(vs), len(zoneFields), vs)
+	}
+
+	resp := zoneResp{"id": "7"}
+	for i, f := range zoneFields {
+		var want string
+		switch f.Kind {
+		case zonewire.Flag:
+			want = zonewire.Enabled
+		case zonewire.Number:
+			want = strconv.Itoa(i + 1)
+		default:
+			want = "value-" + f.Param
+		}
+		if got := vs.Get(f.Param); got != want {
+			t.Errorf("%s (%s) = %q, want %q", f.Param, f.Name, got, want)
+		}
+		resp[f.Param] = vs.Get(f.Param)
+	}
+	if got := resp.ToZone(); got != z {
+		t.Errorf("ToZone = %+v, want %+v", got, z)
+	}
+	if got := Diff(z, Zone{}).Update(7).Patch(Zone{ID: 7, CunstomSSLCert: z.CunstomSSLCert}); got != z {
+		t.Errorf("Patch = %+v, want %+v", got, z)
+	}
+}
+
+func TestZoneFieldsCoverZone(t *testing.T) {
+	mapped := make(map[string]bool, len(zoneFields))
+	params := make(map[string]bool, len(zoneFields))
+	for _, f := range zoneFields {
+		if params[f.Param] {
+			t.Errorf("%s is mapped twice", f.Param)
+		}
+		params[f.Param] = true
+		mapped[f.Name] = true
+	}
+	typ := reflect.TypeOf(Zone{})
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		if name == "ID" || name == "CunstomSSLCert" {
+			continue
+		}
+		if !mapped[name] {
+			t.Errorf("Zone.%s has no wire parameter", name)
+		}
+	}
+}
+
+// TestZoneWireNames pins the KeyCDN parameter names independently of the
+// zonewire table
+func TestZoneWireNames(t *testing.T) {
+	z := Zone{
+		Name:                    "assets",
+		Status:                  ZoneStatusActive,
+		Type:                    ZoneTypePull,
+		ForceDownload:           true,
+		CORS:                    true,
+		Gzip:                    true,
+		Expire:                  60,
+		HTTP2:                   true,
+		SecureToken:             true,
+		SecureTokenKey:          "token-key",
+		SSLCert:                 SSLCertCustom,
+		CustomSSLKey:            "ssl-key",
+		CustomSSLCert:           "ssl-cert",
+		ForceSSL:                true,
+		OriginURL:               "https://origin.example.com",
+		CacheMaxExpire:          1440,
+		CacheIgnoreCacheControl: true,
+		CacheIgnoreQueryString:  true,
+		CacheStripCookies:       true,
+		CachePullKey:            "pull-key",
+		CacheCanonical:          true,
+		CacheRobots:             true,
+		CacheHostHeader:         true,
+		OriginHostHeader:        "bucket.example.com",
+		OriginShield:            true,
+		ImageProcessing:         true,
+		WebP:                    true,
+	}
+	want := url.Values{
+		"name":                    {"assets"},
+		"status":                  {"active"},
+		"type":                    {"pull"},
+		"forcedownload":           {"enabled"},
+		"cors":                    {"enabled"},
+		"gzip":                    {"enabled"},
+		"expire":                  {"60"},
+		"http2":                   {"enabled"},
+		"securetoken":             {"enabled"},
+		"securetokenkey":          {"token-key"},
+		"sslcert":                 {"custom"},
+		"customsslkey":            {"ssl-key"},
+		"customsslcert":           {"ssl-cert"},
+		"forcessl":                {"enabled"},
+		"originurl":               {"https://origin.example.com"},
+		"cachemaxexpire":          {"1440"},
+		"cacheignorecachecontrol": {"enabled"},
+		"cacheignorequerystring":  {"enabled"},
+		"cachestripcookies":       {"enabled"},
+		"cachepullkey":            {"pull-key"},
+		"cachecanonical":          {"enabled"},
+		"cacherobots":             {"enabled"},
+		"cachehostheader":         {"enabled"},
+		"originhostheader":        {"bucket.example.com"},
+		"originshield":            {"enabled"},
+		"imageprocessing":         {"enabled"},
+		"webp":                    {"enabled"},
+	}
+	if got := zoneValues(z); !reflect.DeepEqual(got, want) {
+		t.Errorf("zoneValues = %v, want %v", got, want)
+	}
+
+	c, ts := newTestServer(t, zoneBody)
+	if _, err := c.EditZone(context.Background(), Zone{ID: 1, OriginHostHeader: "bucket.example.com", CacheHostHeader: true, OriginShield: true}); err != nil {
+		t.Fatal(err)
+	}
+	sent := ts.last(t).Form
+	for param, v := range map[string]string{"originhostheader": "bucket.example.com", "cachehostheader": "enabled", "originshield": "enabled"} {
+		if got := sent.Get(param); got != v {
+			t.Errorf("EditZone sent %s = %q, want %q", param, got, v)
+		}
+	}
+
+	resp := zoneResp{"id": "1"}
+	for param := range want {
+		resp[param] = want.Get(param)
+	}
+	z.ID = 1
+	z.CunstomSSLCert = z.CustomSSLCert
+	if got := resp.ToZone(); got != z {
+		t.Errorf("ToZone = %+v, want %+v", got, z)
+	}
+}
@@ -0,0 +1,149 @@
+package keycdn
+
+// ZoneSettings are the settings shared by pull and push zones
+type ZoneSettings struct {
+	ID                      uint64
+	Name                    string
+	Status                  ZoneStatus
+	ForceDownload           bool
+	CORS                    bool
+	Gzip                    bool
+	Expire                  int
+	HTTP2                   bool
+	SecureToken             bool
+	SecureTokenKey          string
+	SSLCert                 string
+	CustomSSLKey            string
+	CustomSSLCert           string
+	ForceSSL                bool
+	CacheMaxExpire          int
+	CacheIgnoreCacheControl bool
+	CacheIgnoreQueryString  bool
+	CacheStripCookies       bool
+	CacheCanonical          bool
+	CacheRobots             bool
+	ImageProcessing         bool
+	WebP                    bool
+}
+
+// PullZone is a zone which fetches content from an origin server on demand
+type PullZone struct {
+	ZoneSettings
+	OriginURL string
+	// OriginHostHeader overrides the Host header sent to the origin
+	OriginHostHeader string
+	// OriginShield routes cache misses through a single shield location
+	OriginShield bool
+	// CachePullKey is sent to the origin so it can recognize pull requests
+	CachePullKey string
+	// CacheHostHeader forwards the Host header of the client to the origin
+	CacheHostHeader bool
+}
+
+// PushZone is a zone which serves files uploaded to the KeyCDN storage.
+// It has no origin settings.
+type PushZone struct {
+	ZoneSettings
+}
+
+// TypedZone is either a PullZone or a PushZone:
+//
+//	switch tz := z.Typed().(type) {
+//	case keycdn.PullZone:
+//		fmt.Println(tz.OriginURL)
+//	case keycdn.PushZone:
+//		fmt.Println(tz.Name)
+//	}
+type TypedZone interface {
+	// Zone returns the zone as the generic Zone type, e.g. for ApplyZone
+	Zone() Zone
+	isTypedZone()
+}
+
+// Typed returns the zone as PullZone or PushZone depending on its type.
+// Zones without type are pull zones, which is the API default.
+func (z Zone) Typed() TypedZone {
+	s := ZoneSettings{
+		ID:                      z.ID,
+		Name:                    z.Name,
+		Status:                  z.Status,
+		ForceDownload:           z.ForceDownload,
+		CORS:                    z.CORS,
+		Gzip:                    z.Gzip,
+		Expire:                  z.Expire,
+		HTTP2:                   z.HTTP2,
+		SecureToken:             z.SecureToken,
+		SecureTokenKey:          z.SecureTokenKey,
+		SSLCert:                 z.SSLCert,
+		CustomSSLKey:            z.CustomSSLKey,
+		CustomSSLCert:           *z.customSSLCert(),
+		ForceSSL:                z.ForceSSL,
+		CacheMaxExpire:          z.CacheMaxExpire,
+		CacheIgnoreCacheControl: z.CacheIgnoreCacheControl,
+		CacheIgnoreQueryString:  z.CacheIgnoreQueryString,
+		CacheStripCookies:       z.CacheStripCookies,
+		CacheCanonical:          z.CacheCanonical,
+		CacheRobots:             z.CacheRobots,
+		ImageProcessing:         z.ImageProcessing,
+		WebP:                    z.WebP,
+	}
+	if z.Type == ZoneTypePush {
+		return PushZone{ZoneSettings: s}
+	}
+	return PullZone{
+		ZoneSettings:     s,
+		OriginURL:        z.OriginURL,
+		OriginHostHeader: z.OriginHostHeader,
+		OriginShield:     z.OriginShield,
+		CachePullKey:     z.CachePullKey,
+		CacheHostHeader:  z.CacheHostHeader,
+	}
+}
+
+// Zone implements TypedZone
+func (p PullZone) Zone() Zone {
+	z := p.ZoneSettings.zone(ZoneTypePull)
+	z.OriginURL = p.OriginURL
+	z.OriginHostHeader = p.OriginHostHeader
+	z.OriginShield = p.OriginShield
+	z.CachePullKey = p.CachePullKey
+	z.CacheHostHeader = p.CacheHostHeader
+	return z
+}
+
+// Zone implements TypedZone
+func (p PushZone) Zone() Zone {
+	return p.ZoneSettings.zone(ZoneTypePush)
+}
+
+func (PullZone) isTypedZone() {}
+func (PushZone) isTypedZone() {}
+
+func (s ZoneSettings) zone(t ZoneType) Zone {
+	return Zone{
+		ID:                      s.ID,
+		Name:                    s.Name,
+		Status:                  s.Status,
+		Type:                    t,
+		ForceDownload:           s.ForceDownload,
+		CORS:                    s.CORS,
+		Gzip:                    s.Gzip,
+		Expire:                  s.Expire,
+		HTTP2:                   s.HTTP2,
+		SecureToken:             s.SecureToken,
+		SecureTokenKey:          s.SecureTokenKey,
+		SSLCert:                 s.SSLCert,
+		CustomSSLKey:            s.CustomSSLKey,
+		CustomSSLCert:           s.CustomSSLCert,
+		CunstomSSLCert:          s.CustomSSLCert,
+		ForceSSL:                s.ForceSSL,
+		CacheMaxExpire:          s.CacheMaxExpire,
+		CacheIgnoreCacheControl: s.CacheIgnoreCacheControl,
+		CacheIgnoreQueryString:  s.CacheIgnoreQueryString,
+		CacheStripCookies:       s.CacheStripCookies,
+		CacheCanonical:          s.CacheCanonical,
+		CacheRobots:             s.CacheRobots,
+		ImageProcessing:         s.ImageProcessing,
+		WebP:                    s.WebP,
+	}
+}
@@ -0,0 +1,29 @@
+package keycdn
+
+import (
+	"sort"
+)
+
+// ZonesByName returns the zones ordered by name and zones of the same name
+// by ID, e.g. to list the result of ZonesFiltered:
+//
+//	zones, err := c.ZonesFiltered(ctx, keycdn.ZoneFilter{Status: keycdn.ZoneStatusActive, Type: keycdn.ZoneTypePull})
+//	if err != nil {
+//		return err
+//	}
+//	for _, z := range keycdn.ZonesByName(zones) {
+//		fmt.Println(z.Name)
+//	}
+func ZonesByName(zones map[uint64]Zone) []Zone {
+	ret := make([]Zone, 0, len(zones))
+	for _, z := range zones {
+		ret = append(ret, z)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Name != ret[j].Name {
+			return ret[i].Name < ret[j].Name
+		}
+		return ret[i].ID < ret[j].ID
+	})
+	return ret
+}
@@ -0,0 +1,38 @@
+package keycdn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestZoneFilterMatch(t *testing.T) {
+	z := Zone{ID: 1, Name: "assets", Status: ZoneStatusActive, Type: ZoneTypePull}
+	for filter, want := range map[ZoneFilter]bool{
+		{}:                         true,
+		{Status: ZoneStatusActive}: true,
+		{Type: ZoneTypePull}:       true,
+		{Status: ZoneStatusActive, Type: ZoneTypePull}: true,
+		{Status: ZoneStatusInactive}:                   false,
+		{Status: ZoneStatusActive, Type: ZoneTypePush}: false,
+	} {
+		if got := filter.Match(z); got != want {
+			t.Errorf("%+v.Match = %t, want %t", filter, got, want)
+		}
+	}
+}
+
+func TestZonesByName(t *testing.T) {
+	zones := map[uint64]Zone{
+		1: {ID: 1, Name: "b"},
+		2: {ID: 2, Name: "a"},
+		3: {ID: 3, Name: "c"},
+		4: {ID: 4, Name: "a"},
+	}
+	var got []uint64
+	for _, z := range ZonesByName(zones) {
+		got = append(got, z.ID)
+	}
+	if want := []uint64{2, 4, 1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ZonesByName = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package keycdn
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ZoneReferrer is a domain which is allowed to embed content of a zone. Once
+// a zone has referrers, requests with other referrers are denied.
+type ZoneReferrer struct {
+	ID     uint64
+	ZoneID uint64
+	Name   string
+}
+
+type zoneReferrerResp map[string]string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *zoneReferrerResp) UnmarshalJSON(b []byte) error {
+	m, err := unmarshalFlexMap(b)
+	*r = m
+	return err
+}
+
+// ToZoneReferrer converts a zone referrer response to a proper ZoneReferrer
+// object
+func (r zoneReferrerResp) ToZoneReferrer() ZoneReferrer {
+	ref := ZoneReferrer{
+		Name: r["name"],
+	}
+	if id, err := strconv.ParseUint(r["id"], 10, 64); err == nil {
+		ref.ID = id
+	}
+	if id, err := strconv.ParseUint(r["zone_id"], 10, 64); err == nil {
+		ref.ZoneID = id
+	}
+	return ref
+}
+
+// ZoneReferrers returns the referrers of all zones
+func (c *Client) ZoneReferrers(ctx context.Context) ([]ZoneReferrer, error) {
+	var refs []ZoneReferrer
+	it := listIterator(ctx, c, "/zonereferrers.json", nil, "zonereferrers", zoneReferrerResp.ToZoneReferrer)
+	for it.Next() {
+		refs = append(refs, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to list zone referrers: %w", err)
+	}
+	return refs, nil
+}
+
+// AddZoneReferrer allows the domain name as referrer of a zone and returns
+// the new referrer
+func (c *Client) AddZoneReferrer(ctx context.Context, zoneID uint64, name string) (ZoneReferrer, error) {
+	vs := url.Values{}
+	vs.Set("zone_id", strconv.FormatUint(zoneID, 10))
+	vs.Set("name", name)
+	b, err := c.post(ctx, "/zonereferrers.json", vs, encodingForm)
+	if err != nil {
+		return ZoneReferrer{}, err
+	}
+	if c.dryRun {
+		return ZoneReferrer{ZoneID: zoneID, Name: name}, nil
+	}
+	op := fmt.Sprintf("Failed to add referrer %q to Zone %d", name, zoneID)
+	r, err := decodeData[zoneReferrerResp](c, "/zonereferrers.json", b, "zonereferrer", op)
+	if err != nil {
+		return ZoneReferrer{}, err
+	}
+	return r.ToZoneReferrer(), nil
+}
+
+// DeleteZoneReferrer removes a zone referrer
+func (c *Client) DeleteZoneReferrer(ctx context.Context, id uint64) error {
+	file := "/zonereferrers/" + strconv.FormatUint(id, 10) + ".json"
+	b, err := c.delete(ctx, file, nil)
+	if err != nil {
+		return err
+	}
+	return c.checkResponse(file, b, fmt.Sprintf("Failed to delete zone referrer %d", id))
+}
+
+// SetZoneReferrers replaces the referrers of a zone with the given names.
+// Referrers which already exist are kept, missing ones are added before
+// the others are deleted, so the zone is never left without any referrer
+// in between. The resulting referrers are returned.
+func (c *Client) SetZoneReferrers(ctx context.Context, zoneID uint64, names []string) ([]ZoneReferrer, error) {
+	all, err := c.ZoneReferrers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]ZoneReferrer)
+	for _, r := range all {
+		if r.ZoneID == zoneID {
+			existing[r.Name] = r
+		}
+	}
+
+	wanted := make(map[string]bool, len(names))
+	refs := make([]ZoneReferrer, 0, len(names))
+	for _, name := range names {
+		if wanted[name] {
+			continue
+		}
+		wanted[name] = true
+		if r, found := existing[name]; found {
+			refs = append(refs, r)
+			continue
+		}
+		r, err := c.AddZoneReferrer(ctx, zoneID, name)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, r)
+	}
+	for name, r := range existing {
+		if wanted[name] {
+			continue
+		}
+		if err := c.DeleteZoneReferrer(ctx, r.ID); err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,268 @@
+package keycdn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxPollInterval caps the backoff of WaitForZoneActive
+	maxPollInterval = 30 * time.Second
+	// zoneNotFoundGrace is how long WaitForZoneActive waits for a new zone
+	// to become visible to the API
+	zoneNotFoundGrace = 30 * time.Second
+)
+
+// Zone returns a single zone. It fails with an error matching
+// ErrZoneNotFound if the zone does not exist.
+func (c *Client) Zone(ctx context.Context, zoneID uint64) (Zone, error) {
+	file := "/zones/" + strconv.FormatUint(zoneID, 10) + ".json"
+	b, err := c.get(ctx, file, nil)
+	if err != nil {
+		return Zone{}, err
+	}
+	z, err := c.decodeZone(file, b, fmt.Sprintf("get Zone %d", zoneID))
+	if errors.Is(err, errNotInData) {
+		// a successful response without zone means it does not exist
+		return Zone{}, fmt.Errorf("Failed to get Zone %d: %w", zoneID, ErrZoneNotFound)
+	}
+	return z, err
+}
+
+// ZoneByName returns the zone with the given name. It fails with an error
+// matching ErrZoneNotFound if there is no such zone and with an error if the
+// name is used by more than one zone.
+func (c *Client) ZoneByName(ctx context.Context, name string) (Zone, error) {
+	z, found, err := c.findZone(ctx, name)
+	if err != nil {
+		return Zone{}, err
+	}
+	if !found {
+		return Zone{}, fmt.Errorf("Failed to get Zone %q: %w", name, ErrZoneNotFound)
+	}
+	return z, nil
+}
+
+// DeleteZone deletes a zone. Deleting a zone which does not exist fails with
+// an error matching ErrZoneNotFound.
+func (c *Client) DeleteZone(ctx context.Context, zoneID uint64) error {
+	file := "/zones/" + strconv.FormatUint(zoneID, 10) + ".json"
+	b, err := c.delete(ctx, file, nil)
+	if err != nil {
+		return err
+	}
+	return c.checkResponse(file, b, fmt.Sprintf("Failed to delete Zone %d", zoneID))
+}
+
+// CreateZoneIfNotExists creates the zone unless a zone with the same name
+// already exists. KeyCDN allows duplicate zone names, so this makes
+// provisioning safely re-runnable. The zone is created with AddZone and all
+// settings of z, see Zone.CreateRequest. The returned bool is true if the
+// zone was created.
+func (c *Client) CreateZoneIfNotExists(ctx context.Context, z Zone) (Zone, bool, error) {
+	existing, found, err := c.findZone(ctx, z.Name)
+	if err != nil {
+		return Zone{}, false, err
+	}
+	if found {
+		return existing, false, nil
+	}
+	created, err := c.AddZone(ctx, z.CreateRequest())
+	if err != nil {
+		return Zone{}, false, err
+	}
+	return created, true, nil
+}
+
+// EditZone updates the zone identified by z.ID with the settings of z. Empty
+// strings, zero numbers and disabled settings are left unchanged, use
+// UpdateZone to disable a setting. The settings are validated first, see
+// ZoneUpdate.Validate.
+func (c *Client) EditZone(ctx context.Context, z Zone) (Zone, error) {
+	return c.editZone(ctx, z.ID, zoneValues(z))
+}
+
+// editZone validates and sends the changed form parameters of a zone
+func (c *Client) editZone(ctx context.Context, zoneID uint64, vs url.Values) (Zone, error) {
+	if err := validationError(validateZoneValues(vs)); err != nil {
+		return Zone{}, fmt.Errorf("Failed to edit Zone %d: %w", zoneID, err)
+	}
+	file := "/zones/" + strconv.FormatUint(zoneID, 10) + ".json"
+	b, err := c.put(ctx, file, vs, encodingForm)
+	if err != nil {
+		return Zone{}, err
+	}
+	if c.dryRun {
+		return dryRunZone(zoneID, vs), nil
+	}
+	return c.decodeZone(file, b, fmt.Sprintf("edit Zone %d", zoneID))
+}
+
+// ApplyZone converges the account towards the desired zone. The zone is
+// looked up by ID if set and by name otherwise. If it does not exist it is
+// created with AddZone and all settings of desired, see Zone.CreateRequest.
+// Otherwise only the fields that differ are updated, treating empty
+// strings, zero numbers and disabled settings in desired as "don't care".
+// The final state of the zone is returned.
+func (c *Client) ApplyZone(ctx context.Context, desired Zone) (Zone, error) {
+	var actual Zone
+	if desired.ID != 0 {
+		z, err := c.Zone(ctx, desired.ID)
+		if err != nil {
+			return Zone{}, err
+		}
+		actual = z
+	} else {
+		z, found, err := c.findZone(ctx, desired.Name)
+		if err != nil {
+			return Zone{}, err
+		}
+		if !found {
+			return c.AddZone(ctx, desired.CreateRequest())
+		}
+		actual = z
+	}
+
+	vs := zoneChanges(desired, actual)
+	if len(vs) == 0 {
+		return actual, nil
+	}
+	return c.editZone(ctx, actual.ID, vs)
+}
+
+// findZone looks up a zone by name. It fails if the name is ambiguous.
+func (c *Client) findZone(ctx context.Context, name string) (Zone, bool, error) {
+	zones, err := c.Zones(ctx)
+	if err != nil {
+		return Zone{}, false, err
+	}
+	var zone Zone
+	found := false
+	for _, z := range zones {
+		if z.Name != name {
+			continue
+		}
+		if found {
+			return Zone{}, false, fmt.Errorf("multiple zones named %q", name)
+		}
+		zone = z
+		found = true
+	}
+	return zone, found, nil
+}
+
+func (c *Client) decodeZone(file string, b []byte, action string) (Zone, error) {
+	z, err := decodeData[zoneResp](c, file, b, "zone", "Failed to "+action)
+	if err != nil {
+		return Zone{}, err
+	}
+	return z.ToZone(), nil
+}
+
+// WaitForZoneActive polls the given zone until its status becomes active or
+// the context expires. It fails right away if the zone is inactive or
+// paused since those zones only become active when enabled explicitly.
+// A zone which is not found is polled for up to 30 seconds since new zones
+// may not be visible to the API right away.
+//
+// pollInterval is the delay before the second poll, it defaults to one
+// second. The delay is doubled after each poll as long as it stays below
+// 30 seconds.
+func (c *Client) WaitForZoneActive(ctx context.Context, zoneID uint64, pollInterval time.Duration) error {
+	return c.WaitForZoneActiveFunc(ctx, zoneID, pollInterval, nil)
+}
+
+// WaitForZoneActiveFunc is like WaitForZoneActive but calls fn, if not nil,
+// with the status of the zone after each poll, e.g. to report progress.
+// pollInterval is doubled after each poll like for WaitForZoneActive.
+func (c *Client) WaitForZoneActiveFunc(ctx context.Context, zoneID uint64, pollInterval time.Duration, fn func(ZoneStatus)) error {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	deadline := c.clock.Now().Add(zoneNotFoundGrace)
+	for {
+		zone, err := c.Zone(ctx, zoneID)
+		switch {
+		case errors.Is(err, ErrZoneNotFound) && c.clock.Now().Before(deadline):
+		case err != nil:
+			return err
+		default:
+			if fn != nil {
+				fn(zone.Status)
+			}
+			switch zone.Status {
+			case ZoneStatusActive:
+				return nil
+			case ZoneStatusInactive, ZoneStatusPaused:
+				return fmt.Errorf("Zone %d is %s", zoneID, zone.Status)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("Zone %d not found: %w", zoneID, ctx.Err())
+			}
+			return fmt.Errorf("Zone %d not active (status %q): %w", zoneID, zone.Status, ctx.Err())
+		case <-c.clock.After(pollInterval):
+		}
+
+		if next := pollInterval * 2; next <= maxPollInterval {
+			pollInterval = next
+		}
+	}
+}
+
+// nameIndex caches the zone name to ID mapping of an account
+type nameIndex struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	index   map[string]uint64
+	fetched time.Time
+}
+
+// ZoneNameIndex returns a map from zone names to zone IDs. It fails if
+// several zones share the same name since names could not be resolved
+// unambiguously. If the client was created with WithNameIndexTTL the index
+// is cached for the given duration.
+func (c *Client) ZoneNameIndex(ctx context.Context) (map[string]uint64, error) {
+	if c.names == nil {
+		return c.zoneNameIndex(ctx)
+	}
+
+	c.names.mu.Lock()
+	defer c.names.mu.Unlock()
+	if c.names.index == nil || c.clock.Now().Sub(c.names.fetched) > c.names.ttl {
+		index, err := c.zoneNameIndex(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.names.index = index
+		c.names.fetched = c.clock.Now()
+	}
+	index := make(map[string]uint64, len(c.names.index))
+	for name, id := range c.names.index {
+		index[name] = id
+	}
+	return index, nil
+}
+
+func (c *Client) zoneNameIndex(ctx context.Context) (map[string]uint64, error) {
+	zones, err := c.Zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]uint64, len(zones))
+	for id, z := range zones {
+		if _, found := index[z.Name]; found {
+			return nil, fmt.Errorf("multiple zones named %q", z.Name)
+		}
+		index[z.Name] = id
+	}
+	return index, nil
+}
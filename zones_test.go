@@ -0,0 +1,162 @@
+package keycdn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const zoneBody = `{"status":"success","description":"","data":{"zone":{"id":"1","name":"assets"}}}`
+
+func TestCreateZoneIfNotExistsSendsAllSettings(t *testing.T) {
+	c, ts := newTestServer(t, `{"status":"success","data":{"zone":{"id":"1","name":"images"}}}`)
+	ts.route(http.MethodGet, "/zones.json", `{"status":"success","data":{"zones":[]}}`)
+	z, created, err := c.CreateZoneIfNotExists(context.Background(), Zone{Name: "images", Gzip: true, Expire: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || z.ID != 1 {
+		t.Errorf("CreateZoneIfNotExists = %+v, %t, want the new zone", z, created)
+	}
+	req := ts.last(t)
+	if req.Method != http.MethodPost || req.Path != "/zones.json" {
+		t.Fatalf("last request = %s %s, want POST /zones.json", req.Method, req.Path)
+	}
+	for param, want := range map[string]string{"name": "images", "gzip": "enabled", "expire": "60", "cors": "disabled", "http2": "disabled"} {
+		if got := req.Form.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+}
+
+func TestAddZoneSendsExplicitFalse(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody)
+	if _, err := c.AddZone(context.Background(), ZoneCreateRequest{Name: "assets", HTTP2: Bool(false)}); err != nil {
+		t.Fatal(err)
+	}
+	form := ts.last(t).Form
+	if got := form.Get("http2"); got != "disabled" {
+		t.Errorf("http2 = %q, want disabled", got)
+	}
+	if _, found := form["cors"]; found {
+		t.Errorf("unset cors was sent")
+	}
+}
+
+// violatedParams returns the parameters reported by a *ValidationError
+func violatedParams(t *testing.T, err error) []string {
+	t.Helper()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("err = %v, want *ValidationError", err)
+	}
+	params := make([]string, 0, len(verr.Violations))
+	for _, v := range verr.Violations {
+		params = append(params, v.Param)
+	}
+	return params
+}
+
+func TestCreateZoneIfNotExistsRejectsInvalidName(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody)
+	ts.route(http.MethodGet, "/zones.json", `{"status":"success","data":{"zones":[]}}`)
+
+	_, created, err := c.CreateZoneIfNotExists(context.Background(), Zone{Name: "Not Valid"})
+	if params := violatedParams(t, err); !reflect.DeepEqual(params, []string{"name"}) || created {
+		t.Errorf("violations of %v, created %t, want only name and nothing created", params, created)
+	}
+	if n := ts.count(http.MethodPost); n != 0 {
+		t.Errorf("%d zones created", n)
+	}
+}
+
+func TestEditZoneRejectsInvalidOrigin(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody)
+
+	_, err := c.EditZone(context.Background(), Zone{ID: 1, Name: "assets", OriginURL: "ftp://example.com"})
+	if params := violatedParams(t, err); !reflect.DeepEqual(params, []string{"originurl"}) {
+		t.Errorf("violations of %v, want only originurl", params)
+	}
+	if n := ts.count(http.MethodPut); n != 0 {
+		t.Errorf("%d zones updated", n)
+	}
+}
+
+func TestApplyZoneRejectsInvalidOrigin(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody)
+	ts.route(http.MethodGet, "/zones.json", `{"status":"success","data":{"zones":[{"id":"1","name":"assets"}]}}`)
+
+	_, err := c.ApplyZone(context.Background(), Zone{Name: "assets", OriginURL: "ftp://example.com"})
+	if params := violatedParams(t, err); !reflect.DeepEqual(params, []string{"originurl"}) {
+		t.Errorf("violations of %v, want only originurl", params)
+	}
+	if n := ts.count(http.MethodPost) + ts.count(http.MethodPut); n != 0 {
+		t.Errorf("%d zones created or updated", n)
+	}
+}
+
+func TestAddZoneRequiresName(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody)
+
+	_, err := c.AddZone(context.Background(), ZoneCreateRequest{OriginURL: "https://example.com"})
+	if params := violatedParams(t, err); !reflect.DeepEqual(params, []string{"name"}) {
+		t.Errorf("violations of %v, want only name", params)
+	}
+	if n := ts.count(http.MethodPost); n != 0 {
+		t.Errorf("%d zones created", n)
+	}
+}
+
+func TestUpdateZoneRejectsNegativeExpire(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody)
+
+	_, err := c.UpdateZone(context.Background(), NewZoneUpdate(1).SetExpire(-5))
+	if params := violatedParams(t, err); !reflect.DeepEqual(params, []string{"expire"}) {
+		t.Errorf("violations of %v, want only expire", params)
+	}
+	if n := ts.count(http.MethodPut); n != 0 {
+		t.Errorf("%d zones updated", n)
+	}
+}
+
+// assertBodylessDelete checks that the last request was a DELETE of path
+// without a body
+func assertBodylessDelete(t *testing.T, ts *testServer, path string) {
+	t.Helper()
+	req := ts.last(t)
+	if req.Method != http.MethodDelete || req.Path != path {
+		t.Errorf("request = %s %s, want DELETE %s", req.Method, req.Path, path)
+	}
+	if req.Body != "" {
+		t.Errorf("body = %q, want none", req.Body)
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		t.Errorf("Content-Type = %q, want none", ct)
+	}
+}
+
+func TestDeleteZoneSendsNoBody(t *testing.T) {
+	c, ts := newTestServer(t, `{"status":"success","description":""}`)
+	if err := c.DeleteZone(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	assertBodylessDelete(t, ts, "/zones/1.json")
+}
+
+func TestDeleteZoneAliasSendsNoBody(t *testing.T) {
+	c, ts := newTestServer(t, `{"status":"success","description":""}`)
+	if err := c.DeleteZoneAlias(context.Background(), 7); err != nil {
+		t.Fatal(err)
+	}
+	assertBodylessDelete(t, ts, "/zonealiases/7.json")
+}
+
+func TestDeleteZoneReferrerSendsNoBody(t *testing.T) {
+	c, ts := newTestServer(t, `{"status":"success","description":""}`)
+	if err := c.DeleteZoneReferrer(context.Background(), 9); err != nil {
+		t.Fatal(err)
+	}
+	assertBodylessDelete(t, ts, "/zonereferrers/9.json")
+}
@@ -0,0 +1,52 @@
+package keycdn
+
+import "fmt"
+
+// ZoneStatus is the status of a zone
+type ZoneStatus string
+
+// Zone states
+const (
+	// ZoneStatusActive is the status of a zone that is ready to serve
+	// traffic
+	ZoneStatusActive   ZoneStatus = "active"
+	ZoneStatusInactive ZoneStatus = "inactive"
+	ZoneStatusPaused   ZoneStatus = "paused"
+)
+
+// ParseZoneStatus parses a zone status as sent by the API
+func ParseZoneStatus(s string) (ZoneStatus, error) {
+	switch st := ZoneStatus(s); st {
+	case ZoneStatusActive, ZoneStatusInactive, ZoneStatusPaused:
+		return st, nil
+	}
+	return "", fmt.Errorf("unknown zone status %q", s)
+}
+
+func (s ZoneStatus) String() string {
+	return string(s)
+}
+
+// ZoneType is the type of a zone
+type ZoneType string
+
+// Zone types
+const (
+	// ZoneTypePull fetches content from an origin server on demand
+	ZoneTypePull ZoneType = "pull"
+	// ZoneTypePush serves content uploaded to KeyCDN
+	ZoneTypePush ZoneType = "push"
+)
+
+// ParseZoneType parses a zone type as sent by the API
+func ParseZoneType(s string) (ZoneType, error) {
+	switch t := ZoneType(s); t {
+	case ZoneTypePull, ZoneTypePush:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown zone type %q", s)
+}
+
+func (t ZoneType) String() string {
+	return string(t)
+}
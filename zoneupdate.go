@@ -0,0 +1,168 @@
+package keycdn
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// ZoneUpdate is a partial update of a zone's settings. Only the fields set
+// through its setters are sent, all other settings remain unchanged.
+//
+//	zone, err := client.UpdateZone(ctx, keycdn.NewZoneUpdate(id).SetGzip(true).SetForceSSL(true))
+type ZoneUpdate struct {
+	zoneID uint64
+	values url.Values
+}
+
+// NewZoneUpdate starts an empty update of the given zone
+func NewZoneUpdate(zoneID uint64) *ZoneUpdate {
+	return &ZoneUpdate{
+		zoneID: zoneID,
+		values: url.Values{},
+	}
+}
+
+// Apply sends the update and returns the updated zone, see
+// Client.UpdateZone
+func (u *ZoneUpdate) Apply(ctx context.Context, c *Client) (Zone, error) {
+	return c.UpdateZone(ctx, u)
+}
+
+// ZoneID returns the ID of the updated zone
+func (u *ZoneUpdate) ZoneID() uint64 {
+	return u.zoneID
+}
+
+// Patch returns z with the settings of the update applied
+func (u *ZoneUpdate) Patch(z Zone) Zone {
+	for _, f := range zoneFields {
+		if _, set := u.values[f.Param]; set {
+			f.parse(&z, u.values.Get(f.Param))
+		}
+	}
+	z.CunstomSSLCert = z.CustomSSLCert
+	return z
+}
+
+// UpdateZone changes only the settings set in u and returns the updated
+// zone. No request is sent if u is empty or invalid, see
+// ZoneUpdate.Validate.
+func (c *Client) UpdateZone(ctx context.Context, u *ZoneUpdate) (Zone, error) {
+	if len(u.values) == 0 {
+		return c.Zone(ctx, u.zoneID)
+	}
+	return c.editZone(ctx, u.zoneID, u.values)
+}
+
+func (u *ZoneUpdate) setString(param, v string) *ZoneUpdate {
+	u.values.Set(param, v)
+	return u
+}
+
+func (u *ZoneUpdate) setFlag(param string, v bool) *ZoneUpdate {
+	if v {
+		return u.setString(param, "enabled")
+	}
+	return u.setString(param, "disabled")
+}
+
+func (u *ZoneUpdate) setNum(param string, v int) *ZoneUpdate {
+	return u.setString(param, strconv.Itoa(v))
+}
+
+// SetName renames the zone
+func (u *ZoneUpdate) SetName(v string) *ZoneUpdate { return u.setString("name", v) }
+
+// SetStatus sets the zone status, e.g. active or inactive
+func (u *ZoneUpdate) SetStatus(v ZoneStatus) *ZoneUpdate { return u.setString("status", string(v)) }
+
+// SetForceDownload sets the force download setting
+func (u *ZoneUpdate) SetForceDownload(v bool) *ZoneUpdate { return u.setFlag("forcedownload", v) }
+
+// SetCORS sets the CORS setting
+func (u *ZoneUpdate) SetCORS(v bool) *ZoneUpdate { return u.setFlag("cors", v) }
+
+// SetGzip sets the gzip setting
+func (u *ZoneUpdate) SetGzip(v bool) *ZoneUpdate { return u.setFlag("gzip", v) }
+
+// SetExpire sets the expire setting in minutes
+func (u *ZoneUpdate) SetExpire(v int) *ZoneUpdate { return u.setNum("expire", v) }
+
+// SetHTTP2 sets the HTTP/2 setting
+func (u *ZoneUpdate) SetHTTP2(v bool) *ZoneUpdate { return u.setFlag("http2", v) }
+
+// SetSecureToken sets the secure token setting
+func (u *ZoneUpdate) SetSecureToken(v bool) *ZoneUpdate { return u.setFlag("securetoken", v) }
+
+// SetSecureTokenKey sets the secure token key
+func (u *ZoneUpdate) SetSecureTokenKey(v string) *ZoneUpdate {
+	return u.setString("securetokenkey", v)
+}
+
+// SetSSLCert sets the certificate type, see the SSLCert constants
+func (u *ZoneUpdate) SetSSLCert(v string) *ZoneUpdate { return u.setString("sslcert", v) }
+
+// SetCustomSSLKey sets the private key of a custom certificate
+func (u *ZoneUpdate) SetCustomSSLKey(v string) *ZoneUpdate { return u.setString("customsslkey", v) }
+
+// SetCustomSSLCert sets the custom certificate
+func (u *ZoneUpdate) SetCustomSSLCert(v string) *ZoneUpdate {
+	return u.setString("customsslcert", v)
+}
+
+// SetForceSSL sets the force SSL setting
+func (u *ZoneUpdate) SetForceSSL(v bool) *ZoneUpdate { return u.setFlag("forcessl", v) }
+
+// SetOriginURL sets the origin URL of a pull zone
+func (u *ZoneUpdate) SetOriginURL(v string) *ZoneUpdate { return u.setString("originurl", v) }
+
+// SetCacheMaxExpire sets the max expire setting in minutes
+func (u *ZoneUpdate) SetCacheMaxExpire(v int) *ZoneUpdate { return u.setNum("cachemaxexpire", v) }
+
+// SetCacheIgnoreCacheControl sets the ignore cache control setting
+func (u *ZoneUpdate) SetCacheIgnoreCacheControl(v bool) *ZoneUpdate {
+	return u.setFlag("cacheignorecachecontrol", v)
+}
+
+// SetCacheIgnoreQueryString sets the ignore query string setting
+func (u *ZoneUpdate) SetCacheIgnoreQueryString(v bool) *ZoneUpdate {
+	return u.setFlag("cacheignorequerystring", v)
+}
+
+// SetCacheStripCookies sets the strip cookies setting
+func (u *ZoneUpdate) SetCacheStripCookies(v bool) *ZoneUpdate {
+	return u.setFlag("cachestripcookies", v)
+}
+
+// SetCachePullKey sets the key sent to the origin on pull requests
+func (u *ZoneUpdate) SetCachePullKey(v string) *ZoneUpdate {
+	return u.setString("cachepullkey", v)
+}
+
+// SetCacheCanonical sets the canonical header setting
+func (u *ZoneUpdate) SetCacheCanonical(v bool) *ZoneUpdate { return u.setFlag("cachecanonical", v) }
+
+// SetCacheRobots sets the robots.txt setting
+func (u *ZoneUpdate) SetCacheRobots(v bool) *ZoneUpdate { return u.setFlag("cacherobots", v) }
+
+// SetCacheHostHeader sets whether the Host header is forwarded to the origin
+func (u *ZoneUpdate) SetCacheHostHeader(v bool) *ZoneUpdate {
+	return u.setFlag("cachehostheader", v)
+}
+
+// SetOriginHostHeader overrides the Host header sent to the origin
+func (u *ZoneUpdate) SetOriginHostHeader(v string) *ZoneUpdate {
+	return u.setString("originhostheader", v)
+}
+
+// SetOriginShield enables or disables the origin shield of a pull zone
+func (u *ZoneUpdate) SetOriginShield(v bool) *ZoneUpdate { return u.setFlag("originshield", v) }
+
+// SetImageProcessing sets the image processing setting
+func (u *ZoneUpdate) SetImageProcessing(v bool) *ZoneUpdate {
+	return u.setFlag("imageprocessing", v)
+}
+
+// SetWebP sets whether images are converted to WebP
+func (u *ZoneUpdate) SetWebP(v bool) *ZoneUpdate { return u.setFlag("webp", v) }
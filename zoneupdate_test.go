@@ -0,0 +1,40 @@
+package keycdn
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestZoneUpdateSendsOnlySetFields(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody)
+	u := NewZoneUpdate(1).SetOriginURL("https://example.com").SetCORS(false).SetExpire(0)
+	if _, err := c.UpdateZone(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+
+	req := ts.last(t)
+	if req.Method != http.MethodPut || req.Path != "/zones/1.json" {
+		t.Errorf("request = %s %s, want PUT /zones/1.json", req.Method, req.Path)
+	}
+	want := url.Values{
+		"originurl": {"https://example.com"},
+		"cors":      {"disabled"},
+		"expire":    {"0"},
+	}
+	if !reflect.DeepEqual(req.Form, want) {
+		t.Errorf("form = %v, want %v", req.Form, want)
+	}
+}
+
+func TestEmptyZoneUpdateSendsNothing(t *testing.T) {
+	c, ts := newTestServer(t, zoneBody)
+	if _, err := c.UpdateZone(context.Background(), NewZoneUpdate(1)); err != nil {
+		t.Fatal(err)
+	}
+	if n := ts.count(http.MethodPut); n != 0 {
+		t.Errorf("%d PUT requests sent for an empty update", n)
+	}
+}